@@ -25,3 +25,14 @@ echo ""
 echo "=== Test 5: Env vars don't leak without -e ==="
 export SECRET_KEY="should_not_appear"
 fragletc --vein python -c 'import os; print(os.environ.get("SECRET_KEY", "correctly_absent"))'
+
+echo ""
+echo "=== Test 6: --secret forwards the value and masks it in output ==="
+export FRAGLET_TEST_TOKEN="tok-5up3r-s3cret"
+fragletc --vein python --secret FRAGLET_TEST_TOKEN -c 'import os; t = os.environ["FRAGLET_TEST_TOKEN"]; print("token:", t); print("length:", len(t))'
+
+echo ""
+echo "=== Test 7: --secret supplies a param declared in fraglet-meta ==="
+export API_TOKEN="tok-5up3r-s3cret"
+fragletc --vein python --secret API_TOKEN -c '# fraglet-meta: param=api_token:required
+import os; print("param:", os.environ["API_TOKEN"])'
@@ -17,3 +17,19 @@ fragletc -c 'print("test")' --vein nonexistent 2>&1 || true
 echo ""
 echo "=== Test 4: No code source with vein ==="
 fragletc --vein python 2>&1 || true
+
+echo ""
+echo "=== Test 5: Missing required params (reported together, no container started) ==="
+fragletc --vein python -c '# fraglet-meta: param=city:required param=date:required param=units:default=metric' 2>&1 || true
+
+echo ""
+echo "=== Test 6: Typed params are validated before the run ==="
+fragletc --vein python -p n=many -p units=kelvin -c '# fraglet-meta: param=n:type=int param=units:type=enum(metric,imperial)' 2>&1 || true
+
+echo ""
+echo "=== Test 7: File params (@path) must exist on the host ==="
+fragletc --vein python -p data=@./no-such-input.csv -c 'print(1)' 2>&1 || true
+
+echo ""
+echo "=== Test 8: --secret names a host variable that must be set ==="
+env -u FRAGLET_TEST_UNSET_TOKEN fragletc --vein python --secret FRAGLET_TEST_UNSET_TOKEN -c 'print(1)' 2>&1 || true
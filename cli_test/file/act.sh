@@ -27,3 +27,12 @@ EOF
 fragletc --image 100hellos/python:latest --fraglet-path /FRAGLET test_input3.py
 rm -f test_input3.py
 
+
+echo ""
+echo "=== Test 4: Extensionless file with fragletc shebang ==="
+cat > test_shebang <<'EOF2'
+#!/usr/bin/env -S fragletc --image=100hellos/python:latest
+print("Hello from shebang!")
+EOF2
+fragletc test_shebang
+rm -f test_shebang
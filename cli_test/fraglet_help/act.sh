@@ -49,3 +49,15 @@ echo "=== Test: program flags after script stay argv; -p stripped in the middle
 echo ""
 echo "=== Test: after -- , -p passes through to program argv (not gobbled) ==="
 ./prints_argv.py -- -p ghost=value
+
+echo ""
+echo "=== Test: fraglet-meta directives listed with their source ==="
+./directives.py --fraglet-help
+
+echo ""
+echo "=== Test: command-line flags override fraglet-meta directives ==="
+fragletc --timeout=5s --fraglet-help directives.py
+
+echo ""
+echo "=== Test: YAML fraglet-meta block (description, params, examples, directives) ==="
+./meta_block.py --fraglet-help
@@ -0,0 +1,3 @@
+#!/usr/bin/env -S fragletc --vein=python
+# fraglet-meta: mode=main network=none timeout=30s env=GREETING=hi retries=3
+print("ok")
@@ -0,0 +1,15 @@
+#!/usr/bin/env -S fragletc --vein=python
+# fraglet-meta: ---
+# description: |
+#   Prints a forecast.
+#   One line per day.
+# params:
+#   city: {required: true, description: City to look up}
+#   days: {type: int, min: 1, default: 3}
+# examples:
+#   - description: Three days in New York
+#     params: {city: New York, days: 3}
+# network: none
+# fraglet-meta: ---
+import os
+print(os.environ["CITY"], os.environ["DAYS"])
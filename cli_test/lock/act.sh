@@ -0,0 +1,37 @@
+#!/bin/sh
+set -e
+
+# fragletc lock over veins whose images are never present locally.
+export FRAGLET_CONFIG=./no-such-dir/config.yml
+export FRAGLET_VEINS_PATH=./veins.yml
+rm -f fraglet.lock
+
+echo "=== Test 1: --check without a lockfile ==="
+fragletc lock --check 2>&1 | sed "s|$PWD|.|" || true
+
+echo ""
+echo "=== Test 2: locking an image that is not present locally writes nothing ==="
+fragletc lock widget 2>&1 | sed "s|$PWD|.|"
+test ! -e fraglet.lock && echo "no fraglet.lock"
+
+echo ""
+echo "=== Test 3: --check reports missing images, changed containers and removed veins ==="
+cat > fraglet.lock <<'LOCK'
+veins:
+  widget:
+    container: registry.example.com/widget:1.0
+    digest: registry.example.com/widget@sha256:1111
+  gadget:
+    container: registry.example.com/gadget:1.0
+    digest: registry.example.com/gadget@sha256:2222
+  gizmo:
+    container: registry.example.com/gizmo:1.0
+    digest: registry.example.com/gizmo@sha256:3333
+LOCK
+fragletc lock --check || echo "exit $?"
+
+echo ""
+echo "=== Test 4: --check of one named vein ==="
+fragletc lock --check gadget || echo "exit $?"
+
+rm -f fraglet.lock
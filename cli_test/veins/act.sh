@@ -0,0 +1,35 @@
+#!/bin/sh
+set -e
+
+# fragletc veins over the embedded veins plus a FRAGLET_VEINS_PATH layer.
+# The user layer points at a missing file so a developer's own veins.yml cannot leak in.
+export FRAGLET_CONFIG=./no-such-dir/config.yml
+export FRAGLET_VEINS_PATH=./veins.yml
+
+echo "=== Test 1: show a vein added by a layer ==="
+fragletc veins show widget
+
+echo ""
+echo "=== Test 2: search ==="
+fragletc veins search "in-house"
+
+echo ""
+echo "=== Test 3: show --json with FRAGLET_VEINS_FORCE_TAG ==="
+FRAGLET_VEINS_FORCE_TAG=2.0 fragletc veins show --json widget | grep '"image"'
+
+echo ""
+echo "=== Test 4: a disabled vein ==="
+fragletc veins show cobol 2>&1 || echo "exit $?"
+
+echo ""
+echo "=== Test 5: no match ==="
+fragletc veins search zzz-no-such-vein 2>&1 || echo "exit $?"
+
+echo ""
+echo "=== Test 6: aliases and letter case ==="
+fragletc veins show PY | head -1
+fragletc veins show c++ | head -1
+
+echo ""
+echo "=== Test 7: a misspelled vein suggests the closest names ==="
+fragletc veins show pyhton 2>&1 || echo "exit $?"
@@ -119,6 +119,10 @@ const usageTemplate = "# Container Usage\n\n" +
 	"# Mount and run\n" +
 	"docker run --rm -v /tmp/fraglet.sh:{{.FragletTempPath}}:ro <container>\n" +
 	"```\n\n" +
+	"## Parameters\n\n" +
+	"`FRAGLET_PARAM_<NAME>=value` is set as `<NAME>=value` unless `<NAME>` is already set.\n" +
+	"`FRAGLET_PARAMFILE_<NAME>=<path>` names a mounted file holding a value too large for an env var:\n" +
+	"`<NAME>_FILE` is set to the path and, when it fits, `<NAME>` to the file's content.\n\n" +
 	"## Documentation\n\n" +
 	"- `usage` - Container usage (this document)\n" +
 	"- `guide` - Authoring guide for writing fraglets\n" +
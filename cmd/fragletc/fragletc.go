@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ofthemachine/fraglet/mcp/tools"
@@ -18,16 +23,17 @@ import (
 	"github.com/ofthemachine/fraglet/pkg/essence"
 	"github.com/ofthemachine/fraglet/pkg/fraglet"
 	"github.com/ofthemachine/fraglet/pkg/guide"
+	"github.com/ofthemachine/fraglet/pkg/runner"
 	"github.com/ofthemachine/fraglet/pkg/vein"
 )
 
 const defaultFragletPath = "/FRAGLET"
 
-// envListFlag implements flag.Value for repeatable -e flags.
-type envListFlag []string
+// listFlag implements flag.Value for repeatable flags (-e, --ulimit).
+type listFlag []string
 
-func (e *envListFlag) String() string { return strings.Join(*e, ",") }
-func (e *envListFlag) Set(val string) error {
+func (e *listFlag) String() string { return strings.Join(*e, ",") }
+func (e *listFlag) Set(val string) error {
 	*e = append(*e, val)
 	return nil
 }
@@ -120,6 +126,12 @@ func main() {
 		case "refresh":
 			handleRefresh()
 			return
+		case "veins":
+			handleVeins()
+			return
+		case "lock":
+			handleLock()
+			return
 		case "guide":
 			handleGuide()
 			return
@@ -143,17 +155,34 @@ func main() {
 	fragletPath := flag.String("fraglet-path", defaultFragletPath, "Path where code is mounted in container")
 	mode := flag.String("mode", "", "Fraglet mode (sets FRAGLET_MODE=mode)")
 	inlineCode := flag.String("c", "", "Program passed in as string (like python -c)")
-	var envFlags envListFlag
+	runnerName := flag.String("runner", "", "Container runner backend (docker, podman); default: automatic")
+	transport := flag.String("transport", "auto", "How code reaches the container: auto, bind or copy")
+	network := flag.String("network", "", "Container network mode (e.g. none); overrides fraglet-meta network=")
+	timeout := flag.Duration("timeout", 0, "Maximum run time (e.g. 30s); overrides fraglet-meta timeout=")
+	platform := flag.String("platform", "", "Image platform (e.g. linux/arm64); overrides fraglet-meta platform=")
+	var envFlags listFlag
 	flag.Var(&envFlags, "e", "Environment variable to forward (repeatable, e.g. -e FOO -e BAR=val)")
+	var secrets listFlag
+	flag.Var(&secrets, "secret", "Environment variable to forward as a secret: off command lines, masked in output (repeatable)")
+	memory := flag.String("memory", "", "Container memory limit (e.g. 512m, 1g)")
+	cpus := flag.String("cpus", "", "Container CPU limit (e.g. 1.5)")
+	pidsLimit := flag.Int64("pids-limit", 0, "Maximum number of processes in the container")
+	var ulimits listFlag
+	flag.Var(&ulimits, "ulimit", "Container ulimit name=soft[:hard] (repeatable)")
+	maxOutput := flag.String("max-output", "", "Cap on combined stdout+stderr bytes (e.g. 1m); excess is discarded")
+	verbose := flag.Bool("verbose", false, "Report runner, phase timings and how the run ended on stderr")
+	noPrompt := flag.Bool("no-prompt", false, "Never ask for missing params; fail instead (for scripts)")
+	tty := flag.Bool("tty", false, "Run the program on a pseudo-terminal (default when stdin and stdout are terminals)")
 
 	// Short forms
 	flag.StringVar(veinSpec, "v", "", "Vein name with optional mode (short form)")
 	flag.StringVar(image, "i", "", "Container image (short form)")
 	flag.StringVar(mode, "m", "", "Fraglet mode (short form)")
 	flag.StringVar(inlineCode, "code", "", "Program passed in as string (like python -c)")
+	flag.BoolVar(tty, "t", false, "Run the program on a pseudo-terminal (short form)")
 
 	// Preprocess argv for params and help
-	filtered, wantFragletHelp, paramStrs, err := preprocessFragletArgv(os.Args[1:])
+	filtered, fragletHelp, paramStrs, err := preprocessFragletArgv(os.Args[1:])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(2)
@@ -172,8 +201,18 @@ func main() {
 		scriptArgs = args[1:]
 	}
 
-	if wantFragletHelp {
-		handleFragletHelp(scriptFile, *inlineCode)
+	if fragletHelp != "" {
+		handleFragletHelp(fragletHelp, engine.RunOptions{
+			VeinSpec:    *veinSpec,
+			Image:       *image,
+			Mode:        *mode,
+			InlineCode:  *inlineCode,
+			EnvFlags:    envFlags,
+			ScriptFile:  scriptFile,
+			NetworkMode: *network,
+			Timeout:     *timeout,
+			Platform:    *platform,
+		})
 		return
 	}
 
@@ -181,6 +220,14 @@ func main() {
 	if fi, err := os.Stdin.Stat(); err == nil && (fi.Mode()&os.ModeCharDevice) == 0 {
 		stdinReader = os.Stdin
 	}
+	// Interactive by default at a terminal. Stdin alone is not enough: a pseudo-terminal merges
+	// stderr into stdout and writes \r\n, so redirected or piped output stays a plain run.
+	if !ttySet() && engine.IsTerminal(os.Stdin) && engine.IsTerminal(os.Stdout) {
+		*tty = true
+	}
+	if *tty {
+		stdinReader = os.Stdin
+	}
 
 	opts := engine.RunOptions{
 		VeinSpec:    *veinSpec,
@@ -189,20 +236,49 @@ func main() {
 		Mode:        *mode,
 		InlineCode:  *inlineCode,
 		EnvFlags:    envFlags,
+		Secrets:     secrets,
 		ScriptFile:  scriptFile,
 		ScriptArgs:  scriptArgs,
 		Stdin:       stdinReader,
 		ParamStrs:   paramStrs,
+		Runner:      *runnerName,
+		Transport:   *transport,
+		NetworkMode: *network,
+		Timeout:     *timeout,
+		Platform:    *platform,
+		Verbose:     *verbose,
+		// Missing params are asked for only when someone is at the terminal.
+		Prompt: !*noPrompt && engine.IsTerminal(os.Stdin) && engine.IsTerminal(os.Stderr),
+		// Ctrl-C, SIGTERM and SIGHUP reach the program instead of killing fragletc.
+		ForwardSignals: true,
+		TTY:            *tty,
+		Limits: runner.ResourceLimits{
+			Memory:    *memory,
+			CPUs:      *cpus,
+			PidsLimit: *pidsLimit,
+			Ulimits:   ulimits,
+			MaxOutput: *maxOutput,
+		},
 	}
 
 	exitCode, err := engine.Run(context.Background(), opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
 	}
 	os.Exit(exitCode)
 }
 
+// ttySet reports whether -t/--tty was given explicitly (including --tty=false).
+func ttySet() bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "t" || f.Name == "tty" {
+			set = true
+		}
+	})
+	return set
+}
+
 const fragletHelpArg = "--fraglet-help"
 
 // argParser implements a simple stateful parser for argument preprocessing
@@ -212,7 +288,7 @@ type argParser struct {
 	passthrough bool
 	filtered    []string
 	params      []string
-	wantHelp    bool
+	help        string // "" (not requested), "text" or "schema"
 }
 
 func (p *argParser) peek() (string, bool) {
@@ -230,7 +306,9 @@ func (p *argParser) consume() (string, bool) {
 	return arg, ok
 }
 
-func preprocessFragletArgv(args []string) ([]string, bool, []string, error) {
+// preprocessFragletArgv strips -p/--param and --fraglet-help[=schema] from argv before "--". help
+// is "" when --fraglet-help was not given, else "text" or "schema".
+func preprocessFragletArgv(args []string) (filtered []string, help string, params []string, err error) {
 	p := &argParser{args: args}
 
 	for {
@@ -250,7 +328,13 @@ func preprocessFragletArgv(args []string) ([]string, bool, []string, error) {
 			p.filtered = append(p.filtered, arg)
 
 		case arg == fragletHelpArg:
-			p.wantHelp = true
+			p.help = "text"
+
+		case strings.HasPrefix(arg, fragletHelpArg+"="):
+			p.help = arg[len(fragletHelpArg)+1:]
+			if p.help != "text" && p.help != "schema" {
+				return nil, "", nil, fmt.Errorf("%s: unknown format %q (use text or schema)", fragletHelpArg, p.help)
+			}
 
 		case strings.HasPrefix(arg, "--param="):
 			p.params = append(p.params, arg[len("--param="):])
@@ -258,7 +342,7 @@ func preprocessFragletArgv(args []string) ([]string, bool, []string, error) {
 		case arg == "--param":
 			val, ok := p.consume()
 			if !ok {
-				return nil, false, nil, errors.New("--param requires a value")
+				return nil, "", nil, errors.New("--param requires a value")
 			}
 			p.params = append(p.params, val)
 
@@ -268,7 +352,7 @@ func preprocessFragletArgv(args []string) ([]string, bool, []string, error) {
 		case arg == "-p":
 			val, ok := p.consume()
 			if !ok {
-				return nil, false, nil, errors.New("-p requires a value")
+				return nil, "", nil, errors.New("-p requires a value")
 			}
 			p.params = append(p.params, val)
 
@@ -281,11 +365,12 @@ func preprocessFragletArgv(args []string) ([]string, bool, []string, error) {
 		}
 	}
 
-	return p.filtered, p.wantHelp, p.params, nil
+	return p.filtered, p.help, p.params, nil
 }
 
-func handleFragletHelp(scriptFile, inlineCode string) {
-	code := inlineCode
+func handleFragletHelp(format string, opts engine.RunOptions) {
+	scriptFile := opts.ScriptFile
+	code := opts.InlineCode
 	if code == "" && scriptFile != "" {
 		data, err := os.ReadFile(scriptFile)
 		if err != nil {
@@ -299,17 +384,26 @@ func handleFragletHelp(scriptFile, inlineCode string) {
 		os.Exit(1)
 	}
 
-	decls := fraglet.ParseParamDecls(code)
-	desc := fraglet.ParseMetaDescription(code)
+	// printFragletSettings reports malformed fraglet-meta; help shows whatever parsed.
+	meta, _ := fraglet.ParseMeta(code)
+	decls := meta.Params
+	if format == "schema" {
+		out, _ := json.MarshalIndent(fraglet.ParamsSchema(decls), "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	desc := meta.Description
 	label := fragletHelpLabel(scriptFile)
 
 	if desc != "" {
 		fmt.Fprintf(os.Stdout, "%s\n\n", desc)
 	}
+	printFragletSettings(opts, code, label)
 
 	if len(decls) == 0 {
 		fmt.Printf("No parameters declared in %s.\n", label)
-		fmt.Fprintf(os.Stdout, "\nAdd param= under fraglet-meta to list names here; optional description= or d= on its own fraglet-meta line.\n")
+		fmt.Fprintf(os.Stdout, "\nAdd param= under fraglet-meta to list names here; optional description= or d= on its own fraglet-meta line,\n"+
+			"or a YAML block between two \"fraglet-meta: ---\" lines with description:, params: and examples:.\n")
 		return
 	}
 
@@ -321,15 +415,91 @@ func handleFragletHelp(scriptFile, inlineCode string) {
 		} else {
 			parts = append(parts, "optional")
 		}
+		if _, typed := d.Modifiers["type"]; typed {
+			parts = append(parts, d.TypeLabel())
+		}
+		if d.IsSecret() {
+			parts = append(parts, "secret")
+		}
+		for _, m := range []string{"min", "max", "pattern"} {
+			if v, ok := d.Modifiers[m]; ok {
+				parts = append(parts, m+": "+v)
+			}
+		}
 		if def, ok := d.Default(); ok {
 			parts = append(parts, "default: "+def)
 		}
 		modStr := strings.Join(parts, ", ")
 		fmt.Printf("  %-12s (%s)%s\n", d.Alias, modStr, envVarArrow(d))
+		if about := d.Modifiers["description"]; about != "" {
+			fmt.Printf("  %-12s %s\n", "", about)
+		}
 	}
+	printFragletExamples(meta.Examples, label)
 	printFragletInvokeHint(label)
 }
 
+// printFragletExamples lists the block examples: as ready-to-run commands.
+func printFragletExamples(examples []fraglet.Example, label string) {
+	if len(examples) == 0 {
+		return
+	}
+	fmt.Printf("\nExamples:\n")
+	for _, ex := range examples {
+		if ex.Description != "" {
+			fmt.Printf("  %s\n", ex.Description)
+		}
+		names := make([]string, 0, len(ex.Params))
+		for name := range ex.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var args []string
+		for _, name := range names {
+			args = append(args, "-p "+shellQuote(name+"="+ex.Params[name]))
+		}
+		if label == "<inline>" {
+			fmt.Printf("    fragletc --vein=<vein> %s -c '<code>'\n", strings.Join(args, " "))
+		} else {
+			fmt.Printf("    ./%s %s\n", label, strings.Join(args, " "))
+		}
+	}
+}
+
+// shellQuote single-quotes s when it contains characters a shell would interpret.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[](){}<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// printFragletSettings lists the effective execution settings when the fraglet declares
+// fraglet-meta directives, with where each value comes from.
+func printFragletSettings(opts engine.RunOptions, code, label string) {
+	d, err := fraglet.ParseDirectives(code)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if d.Empty() && len(d.Unknown) == 0 {
+		return
+	}
+	settings, err := engine.EffectiveSettings(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Settings for %s:\n", label)
+	for _, st := range settings {
+		fmt.Printf("  %-12s %s (%s)\n", st.Name, st.Value, st.Source)
+	}
+	for _, key := range d.Unknown {
+		fmt.Printf("  unknown fraglet-meta key %q is ignored\n", key)
+	}
+	fmt.Println()
+}
+
 func fragletHelpLabel(scriptFile string) string {
 	if scriptFile == "" {
 		return "<inline>"
@@ -368,7 +538,7 @@ Examples:
   fragletc refresh ada              # Refresh ada vein
   fragletc refresh --all            # Refresh all veins
 
-The command respects FRAGLET_VEINS_PATH environment variable for custom veins.
+The command uses the layered vein registry (see "Veins" in fragletc --help).
 `)
 	}
 
@@ -391,9 +561,9 @@ The command respects FRAGLET_VEINS_PATH environment variable for custom veins.
 		}
 	} else if len(args) > 0 {
 		for _, name := range args {
-			v, ok := registry.Get(name)
-			if !ok {
-				fmt.Fprintf(os.Stderr, "Error: vein not found: %s\n", name)
+			v, err := registry.Resolve(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
 			veinsToRefresh = append(veinsToRefresh, v)
@@ -446,7 +616,7 @@ Examples:
   fragletc guide --mode main ada
   fragletc guide -i my-registry/py:latest
 
-The command respects FRAGLET_VEINS_PATH when resolving the vein name.
+The command uses the layered vein registry (see "Veins" in fragletc --help) to resolve the vein name.
 `)
 }
 
@@ -510,7 +680,7 @@ Examples:
   fragletc essence --mode main ada
   fragletc essence -i my-registry/py:latest
 
-The command respects FRAGLET_VEINS_PATH when resolving the vein name.
+The command uses the layered vein registry (see "Veins" in fragletc --help) to resolve the vein name.
 `)
 }
 
@@ -557,6 +727,14 @@ func handleEssence() {
 func handleMCP() {
 	mcpFlags := flag.NewFlagSet("mcp", flag.ExitOnError)
 	savePath := mcpFlags.String("save", "", "Directory to persist successfully run fraglets (content-addressed); optional")
+	runnerName := mcpFlags.String("runner", "", "Container runner backend for run (docker, podman); default: automatic")
+	memory := mcpFlags.String("memory", tools.DefaultRunLimits.Memory, "Maximum container memory per run")
+	cpus := mcpFlags.String("cpus", tools.DefaultRunLimits.CPUs, "Maximum container CPUs per run")
+	pidsLimit := mcpFlags.Int64("pids-limit", tools.DefaultRunLimits.PidsLimit, "Maximum processes per run")
+	maxOutput := mcpFlags.String("max-output", tools.DefaultRunLimits.MaxOutput, "Maximum combined stdout+stderr per run")
+	poolSize := mcpFlags.Int("pool-size", 0, "Idle pre-started containers kept per vein (docker); 0 disables the pool")
+	poolTTL := mcpFlags.Duration("pool-ttl", 10*time.Minute, "Remove pooled containers idle for this long; 0 keeps them")
+	poolMax := mcpFlags.Int("pool-max", 16, "Maximum pooled containers across all veins; 0 = no cap")
 	mcpFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: fragletc mcp [options]
 
@@ -565,17 +743,59 @@ Start the MCP (Model Context Protocol) server over stdio.
 Options:
   --save path   If set, successfully run fraglets are persisted under path (by lang and content hash).
                 Use with Cursor, Claude Desktop, or any MCP-compatible client.
+  --runner name Container runner backend for the run tool (docker, podman).
+                Default: $FRAGLET_RUNNER, then config.yml, then the first usable backend.
+  --memory, --cpus, --pids-limit, --max-output
+                Per-run resource ceilings (defaults: 1g, 2, 512, 1m). Vein defaults and run
+                input may lower them but never raise them; an empty value removes a ceiling.
+  --pool-size n Keep n pre-started containers per vein so runs skip container startup (docker
+                Engine API only). Each container serves one run and is replaced in the background.
+  --pool-ttl d  Remove pooled containers idle longer than d (default 10m; 0 keeps them).
+  --pool-max n  Cap on pooled containers across all veins (default 16; 0 = no cap).
 
 Examples:
   fragletc mcp
   fragletc mcp --save=$HOME/.fraglet/store
+  fragletc mcp --runner=podman
+  fragletc mcp --memory=2g --max-output=4m
+  fragletc mcp --pool-size=2 --pool-ttl=5m
 `)
 	}
 	_ = mcpFlags.Parse(os.Args[2:])
 	if *savePath != "" {
 		tools.SetRunSavePath(expandSavePath(*savePath))
 	}
-	tools.Server.Run(context.Background(), &mcp.StdioTransport{})
+	if *runnerName != "" {
+		if err := tools.SetRunner(*runnerName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if err := tools.SetRunLimits(runner.ResourceLimits{
+		Memory:    *memory,
+		CPUs:      *cpus,
+		PidsLimit: *pidsLimit,
+		MaxOutput: *maxOutput,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	poolOpts := runner.PoolOptions{
+		Size:     *poolSize,
+		IdleTTL:  *poolTTL,
+		MaxTotal: *poolMax,
+		OnError:  func(err error) { fmt.Fprintf(os.Stderr, "fragletc: %v\n", err) },
+	}
+	if err := tools.SetPool(poolOpts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Stop on client EOF or a signal, then remove pooled containers before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	tools.Server.Run(ctx, &mcp.StdioTransport{})
+	tools.Shutdown()
 }
 
 func expandSavePath(path string) string {
@@ -608,20 +828,68 @@ Flags:
         Fraglet-meta parameters as KEY=value (repeatable; any position before "--"). Forms include
         -p K=V, --param K=V, -p=K=V, --param=K=V, and -pK=V when '=' appears in the suffix.
         Optional encodings: -p key=b64:...  See --fraglet-help on a script for its declarations.
+        -p data=@./input.csv mounts a host file or directory read-only in the container and passes
+        its container path (/fraglet-params/DATA/input.csv); use raw:@... for a literal '@'.
+        Values over 64 KiB reach the program as a file (NAME_FILE) and, when small enough, NAME;
+        this needs fraglet-entrypoint v0.7.0 or later in the image, and older ones are refused.
+  --no-prompt
+        Fail on missing required params instead of asking for them. At a terminal (stdin and
+        stderr), fragletc otherwise prompts for each declared param without a value, showing its
+        description, type and default, with secret params read without echo. Never prompts when
+        stdin is piped.
   --fraglet-path string
         Path where code is mounted in container (default: /FRAGLET; long form only)
   -e string
         Environment variable to forward into container (repeatable)
         Use -e FOO to forward host value, -e FOO=bar for explicit value
-  --fraglet-help
-        Show parameter declarations from fraglet-meta and exit (may appear before or after script-file).
+  --secret NAME
+        Forward host environment variable NAME as a secret (repeatable; long form only). Its value
+        never appears in a command line and is masked as ******** in the program's output. NAME may
+        also supply a param, which is then secret too; params declared param=token:secret are
+        treated the same way (prefer --secret TOKEN over -p token=..., which shows up in ps).
+  --fraglet-help, --fraglet-help=schema
+        Show parameter declarations from fraglet-meta and exit (may appear before or after script-file);
+        =schema prints them as a JSON Schema object instead.
         Like -p/--param, removed from argv before your program runs (any position before "--").
         After "--", --fraglet-help and -p/--param pass through unchanged.
+        Declarations may type their values, which are checked and normalized before the run:
+          param=n:type=int:min=1:max=10  param=units:type=enum(metric,imperial)
+          param=ratio:type=float  param=debug:type=bool  param=cfg:type=json  param=id:pattern=[a-z]+
+          param=data:type=file (the value is a host path, mounted like @path)
+        min/max bound numbers (or a string's length); pattern must match the whole value.
   -m, --mode string
         Fraglet mode (sets FRAGLET_MODE=mode)
+  --runner string
+        Container runner backend: docker or podman. Defaults to $FRAGLET_RUNNER, then "runner:" in
+        $XDG_CONFIG_HOME/fraglet/config.yml, then the first usable of docker, podman.
+        Containers never fall back to running on the host.
+  --transport string
+        How the code reaches the container: bind (mount the host file), copy (upload it into the
+        container before it starts) or auto (default): bind when the daemon is on this machine,
+        copy for a remote DOCKER_HOST or a docker context on another host. A mounted docker.sock
+        (docker-out-of-docker) looks local to auto; use copy there.
+  --network string, --timeout duration, --platform string
+        Container network mode (e.g. none), maximum run time (e.g. 30s; exit 124 when exceeded)
+        and image platform (e.g. linux/arm64). Override the fraglet's fraglet-meta directives.
+  --memory, --cpus, --pids-limit, --ulimit name=soft[:hard]
+        Container resource limits (same syntax as docker run). Override the vein's limits
+        from veins.yml; --ulimit is repeatable.
+  --max-output size
+        Cap on combined stdout+stderr (e.g. 64k, 1m); further output is discarded
+  -t, --tty
+        Run the program on a pseudo-terminal for prompts, curses UIs and REPLs: the terminal is
+        put in raw mode, window resizes are passed on and stderr is merged into stdout. On by
+        default when stdin and stdout are terminals; --tty=false turns it off. Stdout must be a
+        terminal too because a pseudo-terminal merges stderr into stdout and ends lines with
+        \r\n, which would corrupt "fragletc x.py > out.txt" or "| grep"; pass --tty to force it.
+  --verbose
+        After the run, print the runner, image pull/create/execute times and how the run ended
+        (exited, signaled, oom-killed, timeout, cancelled, infra-error) to stderr
 
 Positional:
-  script-file   Path to code file (required if -c not set)
+  script-file   Path to code file (required if -c not set). A "#!/usr/bin/env -S fragletc ..."
+                first line supplies --vein/--image/--mode/-e/-p for flags not given, so
+                "fragletc file" runs it like executing the file would.
   script-args   Tail arguments for your program inside the container
 
 First, -p/--param/--fraglet-help are removed from argv anywhere before a bare "--". Then normal
@@ -629,15 +897,79 @@ flags (-v, -c, …) are parsed and must come before script-file. Example: ./tool
 --profile prod strips -p; --profile and prod are program argv. Use "--" so -p/--param/--fraglet-help
 are not stripped.
 
+Fraglet-meta directives:
+  A fraglet may declare its own execution settings on "fraglet-meta:" comment lines:
+    # fraglet-meta: vein=python mode=main network=none timeout=30s env=API_URL platform=linux/arm64
+  env= forwards a host variable (NAME) or sets one (NAME=value) like -e, and may repeat.
+  Precedence: command-line flags (including those on a fragletc shebang line), then
+  fraglet-meta directives, then vein defaults from veins.yml and file-extension inference. Unknown keys are
+  reported on stderr; --fraglet-help lists the effective settings.
+  The same settings, a multi-line description, params and examples may instead be written as
+  YAML between two "fraglet-meta: ---" lines, in the language's comment syntax:
+    # fraglet-meta: ---
+    # description: |
+    #   Prints a forecast.
+    # params:
+    #   city: {required: true, description: City to look up}
+    #   days: {type: int, min: 1, default: 3}
+    # examples:
+    #   - params: {city: Paris, days: 3}
+    # network: none
+    # fraglet-meta: ---
+  Both forms may be mixed; one-line declarations keep working.
+
+Veins:
+  Veins are read in layers, each adding, overriding or disabling veins of the ones before:
+    1. the veins.yml built into fragletc
+    2. veins.yml next to config.yml (e.g. ~/.config/fraglet/veins.yml)
+    3. .fraglet/veins.yml in the current directory or its nearest parent that has one
+    4. $FRAGLET_VEINS_PATH (a veins file or a directory of them)
+  An entry naming an existing vein changes only the fields it sets; "disabled: true" removes it:
+    veins:
+      - name: python
+        container: registry.example.com/python:3.12
+      - name: cobol
+        disabled: true
+  A name may appear only once per layer.
+  Besides name, container, extensions and limits, a vein may set description, tags, aliases,
+  modes (other modes are then rejected), platform and network (used when neither flags nor
+  fraglet-meta set them) and homepage.
+  Vein names are matched in any letter case and through aliases: a vein's "aliases:" in
+  veins.yml (py, js, golang, c++, ...) and the "aliases:" map in config.yml (e.g. snake: python).
+  An unknown name lists the closest vein names.
+  Without --vein or --image, a script's vein is the one its interpreter line names
+  (#!/usr/bin/env node runs javascript, matched by vein name or alias), otherwise the one
+  claiming its extension; when several claim it, the first alphabetically wins (.bf runs
+  befunge, not brainfuck).
+  A fraglet.lock in the current directory or a parent pins locked veins to image digests
+  (see fragletc lock --help).
+
 Stdin:
   Stdin is always forwarded to the program inside the container.
   Cat data.csv | ./process.py --format=json
 
+Signals:
+  SIGINT (Ctrl-C), SIGTERM and SIGHUP are forwarded to the program, which gets 2s to exit
+  before it is killed. fragletc then exits with 128+signal (130 for Ctrl-C).
+
+Exit status:
+  The program's own exit code, or when fragletc could not run it:
+    1    invalid input (vein, flags, code source, params)
+    70   a local failure on the host, such as writing the temporary fraglet file
+    124  the run timed out
+    125  no usable container runner, engine error or image pull failure
+    126  the container's entrypoint could not be run
+    130  the run was interrupted
+
 Subcommands:
   mcp           Start the MCP (Model Context Protocol) server over stdio
                 Use with Claude Desktop, Cursor, or any MCP-compatible client
+  veins         List, show and search veins (image, extensions, source layer)
+                Use "fragletc veins --help" for details
   refresh       Refresh (pull) container images for veins
                 Use "fragletc refresh --help" for details
+  lock          Pin vein images to digests in fraglet.lock, or --check them for drift
+                Use "fragletc lock --help" for details
   guide         Show fraglet guide (vein registry or --image; flags and vein in any order)
                 Use "fragletc guide --help" for details
   essence       Show fraglet essence (vein registry or --image; flags and vein in any order)
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/ofthemachine/fraglet/pkg/embed"
+	"github.com/ofthemachine/fraglet/pkg/lock"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+func handleLock() {
+	lockFlags := flag.NewFlagSet("lock", flag.ExitOnError)
+	check := lockFlags.Bool("check", false, "Report drift between the lockfile and local images")
+	lockFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: fragletc lock [--check] [vein-name...]
+
+Pin vein images to registry digests in fraglet.lock. While a fraglet.lock is found in the
+current directory or a parent, runs of a locked vein use its pinned digest instead of the tag
+(FRAGLET_VEINS_FORCE_TAG and --image bypass the lock).
+
+Without vein names, the veins already in the lockfile are locked again. Images are read from
+the local image store; pull them first with fragletc refresh.
+
+Options:
+  --check    Compare the lockfile with local images instead of writing it; exits 1 on drift
+
+Examples:
+  fragletc lock python ruby         # Pin python and ruby (creates ./fraglet.lock)
+  fragletc lock                     # Re-pin every locked vein to its current local image
+  fragletc lock --check             # Report locked veins whose local image has moved
+`)
+	}
+
+	var names []string
+	rest := os.Args[2:]
+	for {
+		lockFlags.Parse(rest)
+		if lockFlags.NArg() == 0 {
+			break
+		}
+		names = append(names, lockFlags.Arg(0))
+		rest = lockFlags.Args()[1:]
+	}
+
+	registry, err := vein.LoadAuto(embed.LoadEmbeddedVeins)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading veins: %v\n", err)
+		os.Exit(1)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	path := lock.Find(wd)
+	if path == "" {
+		if *check {
+			fmt.Fprintf(os.Stderr, "Error: no %s in %s or its parents\n", lock.FileName, wd)
+			os.Exit(1)
+		}
+		path = filepath.Join(wd, lock.FileName)
+	}
+	l, err := lock.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Vein names, canonical; none means every locked vein.
+	for i, name := range names {
+		v, err := registry.Resolve(name)
+		if err != nil {
+			if _, locked := l.Veins[name]; !(*check && locked) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			continue // --check reports locked veins that are gone
+		}
+		names[i] = v.Name
+	}
+	if len(names) == 0 {
+		for name := range l.Veins {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = slices.Compact(names)
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s locks no veins yet; name the veins to lock (fragletc lock python)\n", path)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	if *check {
+		drift := false
+		for _, name := range names {
+			status, ok := checkLocked(ctx, registry, l, name)
+			fmt.Printf("%s: %s\n", name, status)
+			drift = drift || !ok
+		}
+		if drift {
+			os.Exit(1)
+		}
+		return
+	}
+
+	failed := false
+	for _, name := range names {
+		v, err := registry.Resolve(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+		img := v.ContainerImage()
+		digests, err := vein.ImageRepoDigests(ctx, img)
+		digest, ok := vein.RepoDigest(img, digests)
+		if err != nil || !ok {
+			fmt.Fprintf(os.Stderr, "Error: %s: %s has no registry digest locally; pull it first (fragletc refresh %s)\n", name, img, name)
+			failed = true
+			continue
+		}
+		l.Veins[name] = lock.Entry{Container: v.Container, Digest: digest}
+		fmt.Printf("%s: %s\n", name, digest)
+	}
+	if failed {
+		fmt.Fprintf(os.Stderr, "%s not written\n", path)
+		os.Exit(1)
+	}
+	if err := l.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
+
+// checkLocked compares one vein's lock entry with the vein and its local image.
+func checkLocked(ctx context.Context, registry *vein.VeinRegistry, l *lock.Lock, name string) (status string, ok bool) {
+	e, locked := l.Veins[name]
+	if !locked {
+		return "not locked", false
+	}
+	v, found := registry.Get(name)
+	if !found {
+		return "locked, but no longer a vein", false
+	}
+	if e.Container != v.Container {
+		return fmt.Sprintf("locked for %s, but the vein now uses %s", e.Container, v.Container), false
+	}
+	img := v.ContainerImage()
+	digests, err := vein.ImageRepoDigests(ctx, img)
+	if err != nil {
+		return fmt.Sprintf("%s is not present locally (locked %s)", img, e.Digest), false
+	}
+	local, ok := vein.RepoDigest(img, digests)
+	if !ok {
+		local = "no registry digest"
+	}
+	if local != e.Digest {
+		return fmt.Sprintf("drift: local %s is %s, locked %s", img, local, e.Digest), false
+	}
+	return "ok " + e.Digest, true
+}
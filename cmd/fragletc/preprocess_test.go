@@ -11,7 +11,7 @@ func TestPreprocessFragletArgv(t *testing.T) {
 		name      string
 		args      []string
 		wantTail  []string
-		wantHelp  bool
+		wantHelp  string
 		wantParam []string
 		wantErr   bool
 	}{
@@ -19,7 +19,18 @@ func TestPreprocessFragletArgv(t *testing.T) {
 			name:     "help only",
 			args:     []string{"--fraglet-help", "a.py"},
 			wantTail: []string{"a.py"},
-			wantHelp: true,
+			wantHelp: "text",
+		},
+		{
+			name:     "help schema",
+			args:     []string{"a.py", "--fraglet-help=schema"},
+			wantTail: []string{"a.py"},
+			wantHelp: "schema",
+		},
+		{
+			name:    "help unknown format",
+			args:    []string{"--fraglet-help=yaml"},
+			wantErr: true,
 		},
 		{
 			name:      "p after script",
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ofthemachine/fraglet/pkg/embed"
+	"github.com/ofthemachine/fraglet/pkg/runner"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+// veinInfo is one vein as shown by "fragletc veins".
+type veinInfo struct {
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description,omitempty"`
+	Container          string                 `json:"container"`
+	Image              string                 `json:"image"` // container after FRAGLET_VEINS_FORCE_TAG / discovery order
+	Local              bool                   `json:"local"` // image present in the local image store
+	Extensions         []string               `json:"extensions,omitempty"`
+	ExtensionConflicts map[string][]string    `json:"extensionConflicts,omitempty"` // ext -> every vein claiming it
+	Aliases            []string               `json:"aliases,omitempty"`
+	Tags               []string               `json:"tags,omitempty"`
+	Modes              []string               `json:"modes,omitempty"`
+	Platform           string                 `json:"platform,omitempty"`
+	Network            string                 `json:"network,omitempty"`
+	Limits             *runner.ResourceLimits `json:"limits,omitempty"`
+	Homepage           string                 `json:"homepage,omitempty"`
+	Sources            []vein.Source          `json:"sources"` // defining layer first, then overriding ones
+}
+
+func newVeinInfo(registry *vein.VeinRegistry, extMap *vein.ExtensionMap, v *vein.Vein) veinInfo {
+	img := v.ContainerImage()
+	info := veinInfo{
+		Name:        v.Name,
+		Description: v.Description,
+		Container:   v.Container,
+		Image:       img,
+		Local:       vein.ImageExistsLocally(img),
+		Extensions:  v.Extensions,
+		Aliases:     v.Aliases,
+		Tags:        v.Tags,
+		Modes:       v.Modes,
+		Platform:    v.Platform,
+		Network:     v.Network,
+		Homepage:    v.Homepage,
+		Sources:     registry.Sources(v.Name),
+	}
+	if !v.Limits.IsZero() {
+		limits := v.Limits
+		info.Limits = &limits
+	}
+	for _, ext := range v.Extensions {
+		if conflicts := extMap.Conflicts(ext); conflicts != nil {
+			if info.ExtensionConflicts == nil {
+				info.ExtensionConflicts = make(map[string][]string)
+			}
+			info.ExtensionConflicts[ext] = conflicts
+		}
+	}
+	return info
+}
+
+// matches reports whether term occurs, case-insensitively, in the vein's name, aliases,
+// extensions, tags, description or container.
+func (info veinInfo) matches(term string) bool {
+	term = strings.ToLower(term)
+	fields := []string{info.Name, info.Description, info.Container}
+	fields = append(fields, info.Aliases...)
+	fields = append(fields, info.Extensions...)
+	fields = append(fields, info.Tags...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), term) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleVeins() {
+	veinsFlags := flag.NewFlagSet("veins", flag.ExitOnError)
+	jsonOut := veinsFlags.Bool("json", false, "Print JSON instead of a table")
+	veinsFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: fragletc veins [list] [--json]
+       fragletc veins show <name> [--json]
+       fragletc veins search <term> [--json]
+
+List, show and search the veins fragletc can run.
+
+Commands:
+  list            All veins (the default)
+  show <name>     One vein in full: container, resolved image, extensions and the veins
+                  sharing them, metadata, limits, and every layer that defined or changed it
+  search <term>   Veins whose name, alias, extension, tag, description or container
+                  contains term (case-insensitive); exits 1 when none match
+
+Options:
+  --json    Print JSON instead of a table
+
+The image column is the container after FRAGLET_VEINS_FORCE_TAG or
+FRAGLET_VEIN_TAG_DISCOVERY_ORDER; local tells whether it is in the local image store.
+Veins are read in layers (see "Veins" in fragletc --help); source names the layer that
+defined each vein, followed by any that changed it.
+
+Examples:
+  fragletc veins
+  fragletc veins show python
+  fragletc veins search lisp --json
+`)
+	}
+
+	// Flags may come before or after the command and its argument.
+	var pos []string
+	rest := os.Args[2:]
+	for {
+		veinsFlags.Parse(rest)
+		if veinsFlags.NArg() == 0 {
+			break
+		}
+		pos = append(pos, veinsFlags.Arg(0))
+		rest = veinsFlags.Args()[1:]
+	}
+
+	cmd := "list"
+	if len(pos) > 0 {
+		cmd, pos = pos[0], pos[1:]
+	}
+	switch {
+	case cmd == "list" && len(pos) == 0:
+	case (cmd == "show" || cmd == "search") && len(pos) == 1:
+	default:
+		veinsFlags.Usage()
+		os.Exit(1)
+	}
+
+	registry, err := vein.LoadAuto(embed.LoadEmbeddedVeins)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading veins: %v\n", err)
+		os.Exit(1)
+	}
+	extMap := vein.NewExtensionMap(registry)
+
+	if cmd == "show" {
+		v, err := registry.Resolve(pos[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		info := newVeinInfo(registry, extMap, v)
+		if *jsonOut {
+			printJSON(info)
+		} else {
+			printVeinDetail(os.Stdout, info)
+		}
+		return
+	}
+
+	infos := []veinInfo{}
+	for _, name := range registry.List() {
+		v, _ := registry.Get(name)
+		info := newVeinInfo(registry, extMap, v)
+		if cmd == "search" && !info.matches(pos[0]) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	// A search with no match exits 1 whatever the output format.
+	noMatch := cmd == "search" && len(infos) == 0
+	if *jsonOut {
+		printJSON(infos)
+		if noMatch {
+			os.Exit(1)
+		}
+		return
+	}
+	if noMatch {
+		fmt.Fprintf(os.Stderr, "No veins match %q\n", pos[0])
+		os.Exit(1)
+	}
+	printVeinTable(os.Stdout, infos)
+}
+
+func printJSON(v any) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+// printVeinTable prints one row per vein; extensions other veins also claim are marked '*'.
+func printVeinTable(w io.Writer, infos []veinInfo) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tIMAGE\tLOCAL\tEXTENSIONS\tSOURCE")
+	marked := false
+	for _, info := range infos {
+		exts := make([]string, len(info.Extensions))
+		for i, ext := range info.Extensions {
+			exts[i] = ext
+			if info.ExtensionConflicts[ext] != nil {
+				exts[i] += "*"
+				marked = true
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", info.Name, info.Image, yesNo(info.Local),
+			orDash(strings.Join(exts, " ")), orDash(sourceLayers(info.Sources)))
+	}
+	tw.Flush()
+	if marked {
+		fmt.Fprintln(w, "\n* also claimed by another vein; see fragletc veins show <name>")
+	}
+}
+
+func printVeinDetail(w io.Writer, info veinInfo) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	row("Name", info.Name)
+	row("Description", info.Description)
+	row("Container", info.Container)
+	row("Image", info.Image)
+	row("Local", yesNo(info.Local))
+	row("Extensions", strings.Join(info.Extensions, " "))
+	for _, ext := range info.Extensions {
+		if conflicts := info.ExtensionConflicts[ext]; conflicts != nil {
+			row("Conflict", fmt.Sprintf("%s is also claimed by %s (inference picks %s)",
+				ext, strings.Join(others(conflicts, info.Name), ", "), conflicts[0]))
+		}
+	}
+	row("Aliases", strings.Join(info.Aliases, ", "))
+	row("Tags", strings.Join(info.Tags, ", "))
+	row("Modes", strings.Join(info.Modes, ", "))
+	row("Platform", info.Platform)
+	row("Network", info.Network)
+	if info.Limits != nil {
+		row("Limits", limitsString(*info.Limits))
+	}
+	row("Homepage", info.Homepage)
+	for i, src := range info.Sources {
+		label := "Overridden by"
+		if i == 0 {
+			label = "Source"
+		}
+		row(label, src.String())
+	}
+	tw.Flush()
+}
+
+// sourceLayers joins the layers of sources: "embedded+project".
+func sourceLayers(sources []vein.Source) string {
+	layers := make([]string, len(sources))
+	for i, src := range sources {
+		layers[i] = src.Layer
+	}
+	return strings.Join(layers, "+")
+}
+
+// limitsString formats the set limits with their veins.yml keys: "memory=512m pids=64".
+func limitsString(l runner.ResourceLimits) string {
+	var parts []string
+	add := func(key, value string) {
+		if value != "" && value != "0" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	add("memory", l.Memory)
+	add("cpus", l.CPUs)
+	add("pids", strconv.FormatInt(l.PidsLimit, 10))
+	for _, u := range l.Ulimits {
+		add("ulimit", u)
+	}
+	add("maxOutput", l.MaxOutput)
+	return strings.Join(parts, " ")
+}
+
+func others(names []string, self string) []string {
+	var out []string
+	for _, n := range names {
+		if n != self {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+)
+
+func TestVeinInfoMatches(t *testing.T) {
+	info := veinInfo{
+		Name:        "python",
+		Description: "Python 3 with numpy",
+		Container:   "100hellos/python:latest",
+		Aliases:     []string{"py"},
+		Extensions:  []string{".py"},
+		Tags:        []string{"scripting"},
+	}
+	for _, term := range []string{"PYTH", "numpy", "100hellos/", ".py", "script"} {
+		if !info.matches(term) {
+			t.Errorf("matches(%q) = false", term)
+		}
+	}
+	if info.matches("lisp") {
+		t.Error(`matches("lisp") = true`)
+	}
+}
+
+func TestLimitsString(t *testing.T) {
+	got := limitsString(runner.ResourceLimits{Memory: "512m", PidsLimit: 64, Ulimits: []string{"nofile=1024"}})
+	if want := "memory=512m pids=64 ulimit=nofile=1024"; got != want {
+		t.Errorf("limitsString() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package params
 
 import (
+	"fmt"
 	"os"
 	"sort"
 	"strings"
@@ -8,6 +9,15 @@ import (
 
 const transportPrefix = "FRAGLET_PARAM_"
 
+// transportFilePrefix marks a value fragletc passed as a mounted file because it was too large for
+// an env var (over fraglet.LargeParamBytes, 64 KiB): FRAGLET_PARAMFILE_<NAME>=<path>.
+const transportFilePrefix = "FRAGLET_PARAMFILE_"
+
+// MaxEnvBytes is the longest "NAME=value" Coerce sets for a file-passed value. Linux refuses to
+// exec a program with any env string of 128 KiB or more (MAX_ARG_STRLEN, including the NUL), so
+// larger values are only available through NAME_FILE.
+const MaxEnvBytes = 128<<10 - 1
+
 // EnvVar is a name=value pair extracted from FRAGLET_PARAM_* transport vars.
 type EnvVar struct {
 	Name  string // bare env var name (prefix stripped, case-preserved)
@@ -16,6 +26,8 @@ type EnvVar struct {
 
 // Coerce scans the process environment for FRAGLET_PARAM_* vars,
 // strips the prefix, applies the no-shadow rule, and returns bare env var pairs.
+// FRAGLET_PARAMFILE_<NAME>=<path> yields NAME_FILE=<path> and, when it fits in MaxEnvBytes,
+// NAME set to the file's content.
 // Transport vars are unset after processing.
 // The entrypoint is dumb: no decoding, no schema, no fraglet-meta parsing.
 // Decoding is the caller's responsibility (fragletc decodes before setting transport).
@@ -25,23 +37,30 @@ func Coerce() ([]EnvVar, error) {
 		transport string // FRAGLET_PARAM_CITY
 		bare      string // CITY
 		value     string // london
+		file      bool   // value is the path of a file holding the real value
 	}
 	var entries []entry
 	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, transportPrefix) {
-			continue
-		}
 		eqIdx := strings.Index(env, "=")
 		if eqIdx < 0 {
 			continue
 		}
 		key := env[:eqIdx]
 		val := env[eqIdx+1:]
-		bare := key[len(transportPrefix):]
+		var bare string
+		var file bool
+		switch {
+		case strings.HasPrefix(key, transportPrefix):
+			bare = key[len(transportPrefix):]
+		case strings.HasPrefix(key, transportFilePrefix):
+			bare, file = key[len(transportFilePrefix):], true
+		default:
+			continue
+		}
 		if bare == "" {
 			continue
 		}
-		entries = append(entries, entry{transport: key, bare: bare, value: val})
+		entries = append(entries, entry{transport: key, bare: bare, value: val, file: file})
 	}
 
 	// Sort for deterministic ordering
@@ -54,6 +73,20 @@ func Coerce() ([]EnvVar, error) {
 		// Always clean up transport var
 		os.Unsetenv(e.transport)
 
+		if e.file {
+			data, err := os.ReadFile(e.value)
+			if err != nil {
+				return nil, fmt.Errorf("param %s: %w", e.bare, err)
+			}
+			if _, exists := os.LookupEnv(e.bare + "_FILE"); !exists {
+				result = append(result, EnvVar{Name: e.bare + "_FILE", Value: e.value})
+			}
+			if len(e.bare)+1+len(data) > MaxEnvBytes {
+				continue
+			}
+			e.value = string(data)
+		}
+
 		// No-shadow: if bare env var already exists, skip
 		if _, exists := os.LookupEnv(e.bare); exists {
 			continue
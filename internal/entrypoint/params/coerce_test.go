@@ -2,6 +2,8 @@ package params
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -131,3 +133,49 @@ func TestCoerce_MultipleParams_Sorted(t *testing.T) {
 		t.Fatalf("got %+v, want sorted ALPHA then ZEBRA", got)
 	}
 }
+
+func TestCoerce_FileValue(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "DOC")
+	large := filepath.Join(dir, "BLOB")
+	if err := os.WriteFile(small, []byte(strings.Repeat("x", 100<<10)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte(strings.Repeat("y", MaxEnvBytes)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("FRAGLET_PARAMFILE_DOC", small)
+	os.Setenv("FRAGLET_PARAMFILE_BLOB", large)
+	defer os.Unsetenv("FRAGLET_PARAMFILE_DOC")
+	defer os.Unsetenv("FRAGLET_PARAMFILE_BLOB")
+
+	got, err := Coerce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// BLOB is too large for the exec environment, so only BLOB_FILE is set; DOC fits and gets both.
+	if len(got) != 3 || got[0] != (EnvVar{"BLOB_FILE", large}) || got[1] != (EnvVar{"DOC_FILE", small}) ||
+		got[2].Name != "DOC" || len(got[2].Value) != 100<<10 {
+		t.Fatalf("got %d vars: %v", len(got), names(got))
+	}
+	if _, exists := os.LookupEnv("FRAGLET_PARAMFILE_DOC"); exists {
+		t.Fatal("transport var should be unset")
+	}
+}
+
+func TestCoerce_FileValueMissing(t *testing.T) {
+	os.Setenv("FRAGLET_PARAMFILE_DOC", filepath.Join(t.TempDir(), "missing"))
+	defer os.Unsetenv("FRAGLET_PARAMFILE_DOC")
+
+	if _, err := Coerce(); err == nil {
+		t.Fatal("expected error for unreadable value file")
+	}
+}
+
+func names(vars []EnvVar) []string {
+	out := make([]string, len(vars))
+	for i, v := range vars {
+		out[i] = v.Name
+	}
+	return out
+}
@@ -20,7 +20,7 @@ var LanguageHelpTool = &mcp.Tool{
 }
 
 type LanguageHelpInput struct {
-	Lang string `json:"lang" jsonschema:"the language (vein) to get the authoring guide for"`
+	Lang string `json:"lang" jsonschema:"the language (vein) to get the authoring guide for; aliases and any letter case are accepted"`
 	Mode string `json:"mode,omitempty" jsonschema:"optional mode; when provided, returns the guide for that mode"`
 }
 
@@ -38,6 +38,9 @@ func LanguageHelp(ctx context.Context, req *mcp.CallToolRequest, input LanguageH
 		return nil, LanguageHelpOutput{}, fmt.Errorf("failed to load veins: %w", err)
 	}
 
+	if v, err := registry.Resolve(input.Lang); err == nil {
+		input.Lang = v.Name
+	}
 	result, err := guide.Run(ctx, registry, input.Lang, input.Mode, "")
 	if err != nil {
 		return nil, LanguageHelpOutput{}, fmt.Errorf("failed to get guide for %s: %w", input.Lang, err)
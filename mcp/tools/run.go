@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ofthemachine/fraglet/pkg/embed"
+	"github.com/ofthemachine/fraglet/pkg/engine"
 	"github.com/ofthemachine/fraglet/pkg/fraglet"
+	"github.com/ofthemachine/fraglet/pkg/redact"
 	"github.com/ofthemachine/fraglet/pkg/runner"
 	"github.com/ofthemachine/fraglet/pkg/save"
 	"github.com/ofthemachine/fraglet/pkg/vein"
@@ -29,7 +33,8 @@ const runToolDescriptionBase = "Execute code snippets for code-based reasoning,
 	"that you must follow. " +
 	"A fraglet is an executable code file that runs in a containerized sandbox. When writing fraglets to disk, use the shebang #!/usr/bin/env -S fragletc --vein=<lang> where <lang> matches the lang parameter. Files with this shebang are directly executable via ./filename. " +
 	"Parameters are passed as env vars FRAGLET_PARAM_<NAME>. The code can access these via OS env APIs. " +
-	"Optional: mode and annotations (key:value tokens, e.g. determinism:deterministic, math:number-theory)."
+	"Optional: mode and annotations (key:value tokens, e.g. determinism:deterministic, math:number-theory). " +
+	"Code may declare its own settings on a fraglet-meta comment line, e.g. # fraglet-meta: mode=main timeout=30s network=none env=NAME=value; call arguments take precedence."
 
 func init() {
 	registry, err := vein.LoadAuto(embed.LoadEmbeddedVeins)
@@ -44,28 +49,61 @@ func init() {
 	RunTool = &mcp.Tool{
 		Name: "run",
 		Description: runToolDescriptionBase +
-			"Supported languages: " + strings.Join(registry.List(), ", ") + ". " +
+			"Supported languages: " + supportedLanguages(registry) + ". " +
 			"Runs are limited to 60s by default; pass timeout_seconds to override. ",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}
 }
 
+// supportedLanguages lists the veins by name for the tool description, each followed by its
+// description and declared modes from veins.yml when it has them.
+func supportedLanguages(registry *vein.VeinRegistry) string {
+	names := registry.List()
+	sort.Strings(names)
+	for i, name := range names {
+		v, _ := registry.Get(name)
+		var about []string
+		if v.Description != "" {
+			about = append(about, v.Description)
+		}
+		if len(v.Modes) > 0 {
+			about = append(about, "modes: "+strings.Join(v.Modes, ", "))
+		}
+		if len(about) > 0 {
+			names[i] += " (" + strings.Join(about, "; ") + ")"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
 const DefaultRunTimeout = 60 * time.Second
 
 type RunInput struct {
-	Lang           string            `json:"lang" jsonschema:"the language (vein) to run the code in"`
+	Lang           string            `json:"lang" jsonschema:"the language (vein) to run the code in; common aliases (py, js, golang, c++) and any letter case are accepted"`
 	Code           string            `json:"code" jsonschema:"the code to run"`
 	TimeoutSeconds int               `json:"timeout_seconds,omitempty" jsonschema:"max execution time in seconds; default 60, 0 means use default"`
 	Mode           string            `json:"mode,omitempty" jsonschema:"optional mode; when provided, uses that execution mode for the container"`
 	Annotations    []string          `json:"annotations,omitempty" jsonschema:"optional key:value tokens (e.g. determinism:deterministic, math:number-theory)"`
-	Params         map[string]string `json:"params,omitempty" jsonschema:"parameters injected as env vars, keyed by alias with optional type prefix (e.g. raw, b64, cb64)"`
+	Params         map[string]string `json:"params,omitempty" jsonschema:"parameters injected as env vars, keyed by alias with optional type prefix (e.g. raw, b64, cb64); a param declared secret (param=token:secret) is masked in the output; a param declared type=file takes the file content, which the code receives as a read-only file whose path is the param value"`
+	Memory         string            `json:"memory,omitempty" jsonschema:"optional container memory limit (e.g. 256m); may only lower the server limit"`
+	CPUs           string            `json:"cpus,omitempty" jsonschema:"optional container CPU limit (e.g. 0.5); may only lower the server limit"`
+	PidsLimit      int64             `json:"pids_limit,omitempty" jsonschema:"optional maximum number of processes; may only lower the server limit"`
+	MaxOutput      string            `json:"max_output,omitempty" jsonschema:"optional cap on combined stdout+stderr (e.g. 64k); may only lower the server limit"`
 }
 
 type RunOutput struct {
-	Stdout   string        `json:"standard_out" jsonschema:"the standard output of the code"`
-	Stderr   string        `json:"standard_error" jsonschema:"the standard error of the code"`
-	ExitCode int           `json:"exit_code" jsonschema:"the exit code of the code"`
-	Duration time.Duration `json:"duration" jsonschema:"the duration of the code execution"`
+	Stdout        string                 `json:"standard_out" jsonschema:"the standard output of the code"`
+	Stderr        string                 `json:"standard_error" jsonschema:"the standard error of the code"`
+	ExitCode      int                    `json:"exit_code" jsonschema:"the exit code of the code"`
+	Duration      time.Duration          `json:"duration" jsonschema:"the duration of the code execution"`
+	Timings       runner.PhaseTimings    `json:"timings" jsonschema:"time spent pulling the image, creating the container and executing the code"`
+	Truncated     bool                   `json:"truncated,omitempty" jsonschema:"true when output exceeded the output cap and was cut"`
+	Termination   string                 `json:"termination,omitempty" jsonschema:"how the run ended: exited, signaled, oom-killed, timeout, cancelled or infra-error"`
+	ErrorCategory string                 `json:"error_category,omitempty" jsonschema:"set when the code could not run to completion: timeout, cancelled, image_pull, daemon, entrypoint, missing_params or invalid_params; exit_code is then not the program's"`
+	MissingParams []string               `json:"missing_params,omitempty" jsonschema:"with error_category missing_params: the required params (declared with param=name:required in fraglet-meta) to add to params before retrying"`
+	InvalidParams []fraglet.ParamProblem `json:"invalid_params,omitempty" jsonschema:"with error_category invalid_params: each param whose value does not fit its declared type=, min=, max= or pattern=, with the reason"`
+	ParamsSchema  map[string]any         `json:"params_schema,omitempty" jsonschema:"with missing_params or invalid_params: JSON Schema of the params the code declares"`
+	Warnings      []string               `json:"warnings,omitempty" jsonschema:"fraglet-meta directives in the code that were unknown or not honoured"`
 }
 
 func Run(ctx context.Context, req *mcp.CallToolRequest, input RunInput) (
@@ -79,12 +117,96 @@ func Run(ctx context.Context, req *mcp.CallToolRequest, input RunInput) (
 		return nil, RunOutput{}, fmt.Errorf("failed to load veins: %w", err)
 	}
 
-	// Get vein by name
-	v, ok := registry.Get(input.Lang)
-	if !ok {
-		return nil, RunOutput{}, fmt.Errorf("vein not found: %s", input.Lang)
+	// Get vein by name or alias; the rest of the run uses its canonical name
+	v, err := registry.Resolve(input.Lang)
+	if err != nil {
+		return nil, RunOutput{}, err
+	}
+	input.Lang = v.Name
+
+	// Resource limits: server ceilings, then vein defaults, then per-call input (which may only lower them)
+	limits, err := resolveRunLimits(v, input)
+	if err != nil {
+		return nil, RunOutput{}, err
+	}
+
+	// The code's own fraglet-meta directives (mode, timeout, network, env, platform)
+	directives, err := fraglet.ParseDirectives(input.Code)
+	if err != nil {
+		return nil, RunOutput{}, err
+	}
+	if name, mode, _ := strings.Cut(directives.Vein, ":"); name != "" {
+		if mv, err := registry.Resolve(name); err == nil {
+			directives.Vein = strings.TrimSuffix(mv.Name+":"+mode, ":")
+		}
+	}
+	settings, warnings := resolveRunSettings(input, directives)
+	settings, veinWarnings := applyVeinDefaults(settings, v)
+	warnings = append(warnings, veinWarnings...)
+	if err := v.CheckMode(settings.mode); err != nil {
+		return nil, RunOutput{}, err
 	}
 
+	// Parse and resolve params; required ones must all be present before a container starts
+	var params fraglet.Params
+	for alias, value := range input.Params {
+		// Callers never name host files, so a leading '@' is part of the value, not a file reference
+		if strings.HasPrefix(value, "@") {
+			value = "raw:" + value
+		}
+		p, err := fraglet.ParseParam(alias + "=" + value)
+		if err != nil {
+			return nil, RunOutput{}, fmt.Errorf("param %q: %w", alias, err)
+		}
+		params = append(params, p)
+	}
+	decls := fraglet.ParseParamDecls(input.Code)
+	if len(decls) > 0 {
+		params, err = params.ResolveAliases(decls)
+		if err != nil {
+			return nil, RunOutput{}, fmt.Errorf("param resolution: %w", err)
+		}
+	}
+	params, err = params.ApplyDecls(decls)
+	if err == nil {
+		params, err = params.Validate(decls)
+	}
+	var paramMounts []runner.VolumeMount
+	if err == nil {
+		var cleanupFiles func()
+		params, paramMounts, cleanupFiles, err = fileParamMounts(params, decls)
+		defer cleanupFiles()
+	}
+	if err != nil {
+		if result, output, ok := paramsErrorResult(err, decls); ok {
+			return result, output, nil
+		}
+		return nil, RunOutput{}, fmt.Errorf("params: %w", err)
+	}
+	// Params declared secret stay off any argv and are masked in everything returned or saved
+	params, secrets := params.SplitSecrets(decls, nil)
+	// Values over fraglet.LargeParamBytes are passed as mounted files rather than env vars
+	paramEnv, valueFiles, cleanupValues, err := params.ToTransport()
+	if err != nil {
+		return nil, RunOutput{}, fmt.Errorf("param transport env: %w", err)
+	}
+	defer cleanupValues()
+	secretEnv, secretFiles, cleanupSecrets, err := secrets.ToTransport()
+	if err != nil {
+		return nil, RunOutput{}, fmt.Errorf("param transport env: %w", err)
+	}
+	defer cleanupSecrets()
+	for _, f := range slices.Concat(valueFiles, secretFiles) {
+		paramMounts = append(paramMounts, runner.VolumeMount{HostPath: f.HostPath, ContainerPath: f.ContainerPath})
+	}
+	var secretValues []string
+	for _, p := range secrets {
+		if v, err := p.Decode(); err == nil {
+			secretValues = append(secretValues, v)
+		}
+	}
+	redactor := redact.New(secretValues...)
+
 	// Write code to temp file
 	tmpFile, cleanup, err := writeTempFile(input.Code)
 	if err != nil {
@@ -92,78 +214,80 @@ func Run(ctx context.Context, req *mcp.CallToolRequest, input RunInput) (
 	}
 	defer cleanup()
 
-	// Apply timeout: default 60s, overridable via timeout_seconds (0 = use default)
-	timeout := DefaultRunTimeout
-	if input.TimeoutSeconds > 0 {
-		timeout = time.Duration(input.TimeoutSeconds) * time.Second
-	}
+	// Apply timeout: default 60s, overridable via timeout_seconds or fraglet-meta timeout=
+	timeout := settings.timeout
 	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Create runner (ContainerImage applies FRAGLET_VEINS_FORCE_TAG if set)
 	img := v.ContainerImage()
-	r := runner.NewRunner(img, "")
-
-	// Build env: optional FRAGLET_MODE when mode is set
-	var envVars []string
-	if input.Mode != "" {
-		envVars = append(envVars, fmt.Sprintf("FRAGLET_MODE=%s", input.Mode))
+	r, err := newRunner(img)
+	if err != nil {
+		return nil, RunOutput{}, err
 	}
 
-	// Parse and resolve params
-	var params fraglet.Params
-	if len(input.Params) > 0 {
-		for alias, value := range input.Params {
-			p, err := fraglet.ParseParam(alias + "=" + value)
-			if err != nil {
-				return nil, RunOutput{}, fmt.Errorf("param %q: %w", alias, err)
-			}
-			params = append(params, p)
-		}
-		decls := fraglet.ParseParamDecls(input.Code)
-		if len(decls) > 0 {
-			var err error
-			params, err = params.ResolveAliases(decls)
-			if err != nil {
-				return nil, RunOutput{}, fmt.Errorf("param resolution: %w", err)
-			}
-		}
-		transportEnv, err := params.ToTransportEnv()
-		if err != nil {
-			return nil, RunOutput{}, fmt.Errorf("param transport env: %w", err)
-		}
-		envVars = append(envVars, transportEnv...)
+	// Build env: optional FRAGLET_MODE when mode is set, then declared env= values
+	var envVars []string
+	if settings.mode != "" {
+		envVars = append(envVars, fmt.Sprintf("FRAGLET_MODE=%s", settings.mode))
 	}
+	envVars = append(envVars, settings.env...)
+	envVars = append(envVars, paramEnv...)
 
 	// Execute with volume mount. Stdin and script args are not passed through the MCP run tool (code-only).
 	spec := runner.RunSpec{
-		Container: img,
-		Env:       envVars,
-		Args:      nil,
-		Volumes: []runner.VolumeMount{
+		Container:   img,
+		Env:         envVars,
+		SecretEnv:   secretEnv,
+		Args:        nil,
+		NetworkMode: settings.network,
+		Platform:    settings.platform,
+		Limits:      limits,
+		Volumes: append([]runner.VolumeMount{
 			{
 				HostPath:      tmpFile,
 				ContainerPath: "/FRAGLET",
 			},
-		},
+		}, paramMounts...),
+	}
+
+	// Values over fraglet.LargeParamBytes travel as files, which older entrypoints ignore
+	if len(valueFiles)+len(secretFiles) > 0 {
+		if err := engine.CheckParamFiles(runCtx, r, spec); err != nil {
+			return nil, RunOutput{}, err
+		}
 	}
 
 	result, err := r.Run(runCtx, spec)
+	errCategory := runner.ErrorCategory(err)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			timeoutMsg := fmt.Sprintf("execution timed out after %s", timeout)
-			result = runner.RunResult{Stderr: timeoutMsg, ExitCode: 124}
-		} else {
-			return nil, RunOutput{}, fmt.Errorf("execution failed: %w", err)
+		if errCategory == "" {
+			return nil, RunOutput{}, fmt.Errorf("execution failed (%s): %w", result.Termination, err)
+		}
+		// The runner has already stopped and removed any container; keep whatever output it produced.
+		// ExitCode stays what the program reported (-1 if it never ran); error_category says why.
+		if result.Stderr != "" && !strings.HasSuffix(result.Stderr, "\n") {
+			result.Stderr += "\n"
+		}
+		switch errCategory {
+		case "timeout":
+			result.Stderr += fmt.Sprintf("execution timed out after %s", timeout)
+		case "cancelled":
+			result.Stderr += "execution cancelled"
+		default:
+			result.Stderr += fmt.Sprintf("execution failed: %v", err)
 		}
 	}
 
+	result.Stdout, result.Stderr = redactor.String(result.Stdout), redactor.String(result.Stderr)
+	code := redactor.String(input.Code)
+
 	// Persist on success when save path is configured (invisible to agent: no path/hash in response)
-	if result.ExitCode == 0 {
+	if err == nil && result.ExitCode == 0 {
 		if saveRoot := getRunSavePath(); saveRoot != "" {
 			imageWithDigest, _ := vein.ResolveImageDigest(runCtx, img)
 			saver := save.NewLocalSave(saveRoot)
-			_ = saver.Save(runCtx, input.Lang, imageWithDigest, input.Mode, input.Annotations, input.Code)
+			_ = saver.Save(runCtx, input.Lang, imageWithDigest, settings.mode, input.Annotations, code)
 		}
 	}
 
@@ -188,34 +312,227 @@ func Run(ctx context.Context, req *mcp.CallToolRequest, input RunInput) (
 		contentParts = append(contentParts, fmt.Sprintf("**Standard Error:**\n%s\n%s\n%s", fence, result.Stderr, fence))
 	}
 
+	if result.Truncated {
+		contentParts = append(contentParts, fmt.Sprintf("**Note:** output truncated at %s", limits.MaxOutput))
+	}
+
+	for _, w := range warnings {
+		contentParts = append(contentParts, fmt.Sprintf("**Warning:** %s", w))
+	}
+
 	// Add execution metadata
 	status := "Success"
-	if result.ExitCode != 0 {
+	if errCategory != "" {
+		status = fmt.Sprintf("Error (%s)", errCategory)
+	} else if result.ExitCode != 0 {
 		status = fmt.Sprintf("Failed (exit code: %d)", result.ExitCode)
 	}
+	if result.Termination != "" && result.Termination != runner.TerminationExited {
+		status += fmt.Sprintf(" — %s", result.Termination)
+	}
 	contentParts = append(contentParts, fmt.Sprintf("**Status:** %s | **Duration:** %s", status, result.Duration.Round(time.Millisecond)))
 
 	// Format code block
-	codeFence := fenceForCodeBlock(input.Code)
-	codeBlock := fmt.Sprintf("%s%s\n%s\n%s", codeFence, input.Lang, input.Code, codeFence)
+	codeFence := fenceForCodeBlock(code)
+	codeBlock := fmt.Sprintf("%s%s\n%s\n%s", codeFence, input.Lang, code, codeFence)
 	formattedContent := fmt.Sprintf("**Code executed in `%s`:**\n\n%s\n\n%s",
 		input.Lang, codeBlock, strings.Join(contentParts, "\n\n"))
 
 	// Log execution to server stderr for client visibility
-	fmt.Fprintf(os.Stderr, "[mcp] run lang=%s mode=%s exit=%d duration=%s\n", input.Lang, input.Mode, result.ExitCode, result.Duration)
+	fmt.Fprintf(os.Stderr, "[mcp] run lang=%s mode=%s exit=%d termination=%s duration=%s pull=%s create=%s execute=%s\n",
+		input.Lang, settings.mode, result.ExitCode, result.Termination, result.Duration,
+		result.Timings.Pull, result.Timings.Create, result.Timings.Execute)
 
 	return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: formattedContent,
-				},
+		IsError: errCategory != "",
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formattedContent,
 			},
-		}, RunOutput{
-			Stdout:   result.Stdout,
-			Stderr:   result.Stderr,
-			ExitCode: result.ExitCode,
-			Duration: result.Duration,
-		}, nil
+		},
+	}, RunOutput{
+		Stdout:        result.Stdout,
+		Stderr:        result.Stderr,
+		ExitCode:      result.ExitCode,
+		Duration:      result.Duration,
+		Timings:       result.Timings,
+		Truncated:     result.Truncated,
+		Termination:   string(result.Termination),
+		ErrorCategory: errCategory,
+		Warnings:      warnings,
+	}, nil
+}
+
+// paramsErrorResult turns a missing- or invalid-params error into a structured result the caller
+// can act on, with the declared params' schema; nothing was run. ok is false for other errors.
+func paramsErrorResult(err error, decls []fraglet.ParamDecl) (*mcp.CallToolResult, RunOutput, bool) {
+	output := RunOutput{ExitCode: -1, ParamsSchema: fraglet.ParamsSchema(decls)}
+	var text string
+	var missing *fraglet.MissingParamsError
+	var invalid *fraglet.InvalidParamsError
+	switch {
+	case errors.As(err, &missing):
+		output.ErrorCategory, output.MissingParams = "missing_params", missing.Missing
+		example := make([]string, len(missing.Missing))
+		for i, name := range missing.Missing {
+			example[i] = fmt.Sprintf("%q: \"...\"", name)
+		}
+		text = fmt.Sprintf("The code declares required params that were not supplied: %s.\n"+
+			"Retry with params: {%s}", strings.Join(missing.Missing, ", "), strings.Join(example, ", "))
+	case errors.As(err, &invalid):
+		output.ErrorCategory, output.InvalidParams = "invalid_params", invalid.Problems
+		lines := make([]string, len(invalid.Problems))
+		for i, p := range invalid.Problems {
+			lines[i] = fmt.Sprintf("- %s: %s", p.Name, p.Reason)
+		}
+		text = "Some params do not fit their declarations:\n" + strings.Join(lines, "\n") +
+			"\nRetry with values matching params_schema."
+	default:
+		return nil, RunOutput{}, false
+	}
+	text = fmt.Sprintf("**Status:** Error (%s) — the code was not run.\n\n%s", output.ErrorCategory, text)
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, output, true
+}
+
+// fileParamMounts writes type=file params, whose values are the file content, to a temporary
+// directory and mounts each read-only; the param then holds its container path. Host paths (@path)
+// are refused: callers send content and never name files on the server. cleanup removes the files.
+func fileParamMounts(params fraglet.Params, decls []fraglet.ParamDecl) (fraglet.Params, []runner.VolumeMount, func(), error) {
+	cleanup := func() {}
+	var problems []fraglet.ParamProblem
+	for _, p := range params {
+		if p.IsFile() {
+			name := strings.ToLower(p.EnvVar)
+			for _, d := range decls {
+				if d.EnvVar == p.EnvVar {
+					name = d.Alias
+				}
+			}
+			problems = append(problems, fraglet.ParamProblem{Name: name,
+				Reason: "host file references (@path) are not accepted; declare the param type=file and pass the file content"})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, nil, cleanup, &fraglet.InvalidParamsError{Problems: problems}
+	}
+	if !slices.ContainsFunc(decls, func(d fraglet.ParamDecl) bool { return d.Type() == fraglet.TypeFile }) {
+		return params, nil, cleanup, nil
+	}
+	dir, err := os.MkdirTemp("", "fraglet-params-*")
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	params, err = params.WriteFiles(decls, dir)
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+	params, files, err := params.FileMounts(decls)
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+	mounts := make([]runner.VolumeMount, len(files))
+	for i, f := range files {
+		mounts[i] = runner.VolumeMount{HostPath: f.HostPath, ContainerPath: f.ContainerPath}
+	}
+	return params, mounts, cleanup, nil
+}
+
+// runSettings are the execution settings of one run after fraglet-meta directives are applied.
+type runSettings struct {
+	mode     string
+	timeout  time.Duration
+	network  string
+	platform string
+	env      []string // NAME=value
+}
+
+// resolveRunSettings fills what the call leaves unset from the code's fraglet-meta directives and
+// explains each directive it does not honour. The call's lang always wins, and directives may not
+// loosen the server's sandbox: only network=none is honoured, and env= must carry its value since
+// the server's own environment is never exposed to submitted code.
+func resolveRunSettings(input RunInput, d fraglet.Directives) (runSettings, []string) {
+	var warnings []string
+	for _, key := range d.Unknown {
+		warnings = append(warnings, fmt.Sprintf("unknown fraglet-meta key %q ignored", key))
+	}
+
+	s := runSettings{mode: input.Mode, timeout: DefaultRunTimeout, platform: d.Platform}
+	metaVein, metaMode, _ := strings.Cut(d.Vein, ":")
+	if metaVein != "" && metaVein != input.Lang {
+		warnings = append(warnings, fmt.Sprintf("fraglet-meta vein=%s ignored; running in %s", d.Vein, input.Lang))
+	} else if s.mode == "" {
+		s.mode = metaMode
+		if s.mode == "" {
+			s.mode = d.Mode
+		}
+	}
+
+	if input.TimeoutSeconds > 0 {
+		s.timeout = time.Duration(input.TimeoutSeconds) * time.Second
+	} else if d.Timeout > 0 {
+		s.timeout = d.Timeout
+	}
+
+	switch d.Network {
+	case "":
+	case "none":
+		s.network = "none"
+	default:
+		warnings = append(warnings, fmt.Sprintf("fraglet-meta network=%s ignored; only network=none is honoured", d.Network))
+	}
+
+	for _, e := range d.Env {
+		if strings.Contains(e, "=") {
+			s.env = append(s.env, e)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("fraglet-meta env=%s ignored; the server's environment is not forwarded (use env=%s=value)", e, e))
+		}
+	}
+	return s, warnings
+}
+
+// applyVeinDefaults fills the network and platform the call and directives leave unset from the
+// vein. Like fraglet-meta, a vein layer may not loosen the server's sandbox: of its network
+// defaults only none is honoured.
+func applyVeinDefaults(s runSettings, v *vein.Vein) (runSettings, []string) {
+	var warnings []string
+	if s.network == "" {
+		switch v.Network {
+		case "":
+		case "none":
+			s.network = "none"
+		default:
+			warnings = append(warnings, fmt.Sprintf("vein %s network=%s ignored; only network=none is honoured", v.Name, v.Network))
+		}
+	}
+	if s.platform == "" {
+		s.platform = v.Platform
+	}
+	return s, warnings
+}
+
+// resolveRunLimits layers vein defaults and the caller's limits over the server ceilings
+// and rejects anything that would exceed them.
+func resolveRunLimits(v *vein.Vein, input RunInput) (runner.ResourceLimits, error) {
+	ceiling := getRunLimits()
+	requested := runner.ResourceLimits{
+		Memory:    input.Memory,
+		CPUs:      input.CPUs,
+		PidsLimit: input.PidsLimit,
+		MaxOutput: input.MaxOutput,
+	}
+	if err := requested.Within(ceiling); err != nil {
+		return runner.ResourceLimits{}, fmt.Errorf("limits: %w", err)
+	}
+	limits := ceiling.Merge(v.Limits).Merge(requested)
+	if err := limits.Within(ceiling); err != nil {
+		return runner.ResourceLimits{}, fmt.Errorf("vein %s limits: %w", v.Name, err)
+	}
+	return limits, nil
 }
 
 func writeTempFile(content string) (string, func(), error) {
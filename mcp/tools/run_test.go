@@ -6,10 +6,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+	"github.com/ofthemachine/fraglet/pkg/runner"
+	"github.com/ofthemachine/fraglet/pkg/vein"
 )
 
 func isDockerAvailable() bool {
@@ -112,6 +117,123 @@ func TestRun_UnsupportedLanguage(t *testing.T) {
 	}
 }
 
+func TestRun_MissingRequiredParams(t *testing.T) {
+	// Rejected before any runner is needed, so no Docker required.
+	result, output, err := Run(context.Background(), nil, RunInput{
+		Lang:   "python",
+		Code:   "# fraglet-meta: param=city:required param=date:required param=units:default=metric\nprint('x')",
+		Params: map[string]string{"units": "imperial"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !result.IsError || output.ErrorCategory != "missing_params" {
+		t.Errorf("IsError = %v, error_category = %q", result.IsError, output.ErrorCategory)
+	}
+	if !slices.Equal(output.MissingParams, []string{"city", "date"}) {
+		t.Errorf("missing_params = %v, want [city date]", output.MissingParams)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, `"city": "..."`) || !strings.Contains(text, `"date": "..."`) {
+		t.Errorf("content does not show how to fix the call:\n%s", text)
+	}
+}
+
+func TestRun_InvalidTypedParams(t *testing.T) {
+	result, output, err := Run(context.Background(), nil, RunInput{
+		Lang:   "python",
+		Code:   "# fraglet-meta: param=n:type=int:max=10 param=units:type=enum(metric,imperial)\nprint('x')",
+		Params: map[string]string{"n": "12", "units": "kelvin"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !result.IsError || output.ErrorCategory != "invalid_params" || len(output.InvalidParams) != 2 {
+		t.Fatalf("IsError = %v, output = %+v", result.IsError, output)
+	}
+	props, _ := output.ParamsSchema["properties"].(map[string]any)
+	if _, ok := props["units"]; !ok {
+		t.Errorf("params_schema = %v", output.ParamsSchema)
+	}
+}
+
+func TestRun_AtSignValueIsLiteral(t *testing.T) {
+	// "@bob" is text for an ordinary param. Without Docker the run then fails in the runner,
+	// but never as invalid_params.
+	result, output, err := Run(context.Background(), nil, RunInput{
+		Lang:   "python",
+		Code:   "# fraglet-meta: param=handle:pattern=^@[a-z]+$\nimport os\nprint(os.environ['HANDLE'])",
+		Params: map[string]string{"handle": "@bob"},
+	})
+	if output.ErrorCategory == "invalid_params" || output.ErrorCategory == "missing_params" {
+		t.Fatalf("error_category = %q, invalid_params = %+v", output.ErrorCategory, output.InvalidParams)
+	}
+	if !isDockerAvailable() {
+		return
+	}
+	if err != nil || result.IsError || strings.TrimSpace(output.Stdout) != "@bob" {
+		t.Errorf("err = %v, stdout = %q, want @bob", err, output.Stdout)
+	}
+}
+
+func TestRun_SecretParamMasked(t *testing.T) {
+	if !isDockerAvailable() {
+		t.Skip("Docker not available, skipping test")
+	}
+	result, output, err := Run(context.Background(), nil, RunInput{
+		Lang:   "python",
+		Code:   "# fraglet-meta: param=token:secret\nimport os\nprint('token is', os.environ['TOKEN'])",
+		Params: map[string]string{"token": "s3cr3t-value"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(output.Stdout, "token is ********") || strings.Contains(output.Stdout, "s3cr3t-value") {
+		t.Errorf("stdout = %q, want the token masked", output.Stdout)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; strings.Contains(text, "s3cr3t-value") {
+		t.Errorf("content shows the secret: %s", text)
+	}
+}
+
+func TestFileParamMounts(t *testing.T) {
+	decls := fraglet.ParseParamDecls("# fraglet-meta: param=input:type=file param=name")
+	params := fraglet.Params{{EnvVar: "INPUT", Encoding: "raw", Value: "a,b\n"}, {EnvVar: "NAME", Encoding: "raw", Value: "x"}}
+	got, mounts, cleanup, err := fileParamMounts(params, decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 1 || mounts[0].ContainerPath != "/fraglet-params/INPUT/input" || got[0].Value != mounts[0].ContainerPath {
+		t.Fatalf("mounts = %+v, params = %v", mounts, got.ToCanonical())
+	}
+	if data, err := os.ReadFile(mounts[0].HostPath); err != nil || string(data) != "a,b\n" {
+		t.Errorf("content = %q, %v", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(mounts[0].HostPath); !os.IsNotExist(err) {
+		t.Errorf("file not removed: %v", err)
+	}
+
+	// Callers send content; a host path on the server is never mounted.
+	_, _, cleanup, err = fileParamMounts(fraglet.Params{{EnvVar: "INPUT", Encoding: "file", Value: "/etc/passwd"}}, decls)
+	defer cleanup()
+	if result, output, ok := paramsErrorResult(err, decls); !ok || !result.IsError || output.InvalidParams[0].Name != "input" {
+		t.Errorf("err = %v", err)
+	}
+
+	// Nor is one the code declares as a default; that is not the caller's error either.
+	decls = fraglet.ParseParamDecls("# fraglet-meta: param=input:type=file:default=/etc/passwd param=data:default=@/etc/passwd")
+	params, err = fraglet.Params{}.ApplyDecls(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, mounts, cleanup, err = fileParamMounts(params, decls)
+	defer cleanup()
+	if err != nil || len(mounts) != 0 {
+		t.Errorf("defaults: mounts = %+v, err = %v", mounts, err)
+	}
+}
+
 func TestLanguageHelp_Python(t *testing.T) {
 	if !isDockerAvailable() {
 		t.Skip("Docker not available, skipping test")
@@ -390,3 +512,110 @@ func TestRun_SavedFileHasModeWhenExplicit(t *testing.T) {
 		t.Errorf("saved file must contain --mode=main when mode set; got:\n%s", data)
 	}
 }
+
+func TestResolveRunLimits(t *testing.T) {
+	v := &vein.Vein{Name: "java", Limits: runner.ResourceLimits{Memory: "768m"}}
+
+	// The default ceilings apply where neither the vein nor the input sets a limit.
+	got, err := resolveRunLimits(v, RunInput{MaxOutput: "64k"})
+	if err != nil {
+		t.Fatalf("resolveRunLimits: %v", err)
+	}
+	if got.Memory != "768m" || got.MaxOutput != "64k" || got.CPUs != DefaultRunLimits.CPUs || got.PidsLimit != DefaultRunLimits.PidsLimit {
+		t.Errorf("limits = %+v", got)
+	}
+
+	// With no ceilings configured, only the vein and the input limit the run.
+	if err := SetRunLimits(runner.ResourceLimits{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetRunLimits(DefaultRunLimits) })
+	got, err = resolveRunLimits(v, RunInput{MaxOutput: "64k"})
+	if err != nil {
+		t.Fatalf("resolveRunLimits: %v", err)
+	}
+	if got.Memory != "768m" || got.MaxOutput != "64k" || got.CPUs != "" || got.PidsLimit != 0 {
+		t.Errorf("limits = %+v", got)
+	}
+
+	ceiling := runner.ResourceLimits{Memory: "1g", CPUs: "2", PidsLimit: 512, MaxOutput: "1m"}
+	if err := SetRunLimits(ceiling); err != nil {
+		t.Fatal(err)
+	}
+	got, err = resolveRunLimits(v, RunInput{MaxOutput: "64k"})
+	if err != nil {
+		t.Fatalf("resolveRunLimits: %v", err)
+	}
+	if got.Memory != "768m" || got.MaxOutput != "64k" || got.CPUs != "2" || got.PidsLimit != 512 {
+		t.Errorf("limits = %+v", got)
+	}
+
+	if _, err := resolveRunLimits(v, RunInput{Memory: "8g"}); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("input above ceiling: err = %v", err)
+	}
+
+	greedy := &vein.Vein{Name: "greedy", Limits: runner.ResourceLimits{CPUs: "16"}}
+	if _, err := resolveRunLimits(greedy, RunInput{}); err == nil || !strings.Contains(err.Error(), "greedy") {
+		t.Errorf("vein above ceiling: err = %v", err)
+	}
+}
+
+func TestResolveRunSettings(t *testing.T) {
+	d, err := fraglet.ParseDirectives("# fraglet-meta: vein=python:main timeout=5s network=none env=A=1 env=HOME platform=linux/arm64 retries=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, warnings := resolveRunSettings(RunInput{Lang: "python"}, d)
+	if got.mode != "main" || got.timeout != 5*time.Second || got.network != "none" || got.platform != "linux/arm64" {
+		t.Errorf("settings = %+v", got)
+	}
+	if !slices.Equal(got.env, []string{"A=1"}) {
+		t.Errorf("env = %v, want [A=1]: the server's HOME must not be forwarded", got.env)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "retries") || !strings.Contains(warnings[1], "HOME") {
+		t.Errorf("warnings = %q", warnings)
+	}
+
+	// Call arguments win; a directive for another vein keeps its mode to itself; network may only tighten.
+	d, _ = fraglet.ParseDirectives("# fraglet-meta: vein=ruby mode=main timeout=5s network=host")
+	got, warnings = resolveRunSettings(RunInput{Lang: "python", TimeoutSeconds: 9}, d)
+	if got.mode != "" || got.timeout != 9*time.Second || got.network != "" {
+		t.Errorf("settings = %+v", got)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "vein=ruby") || !strings.Contains(warnings[1], "network=host") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestApplyVeinDefaults(t *testing.T) {
+	got, warnings := applyVeinDefaults(runSettings{}, &vein.Vein{Name: "python", Network: "none", Platform: "linux/amd64"})
+	if got.network != "none" || got.platform != "linux/amd64" || len(warnings) != 0 {
+		t.Errorf("settings = %+v, warnings = %q", got, warnings)
+	}
+
+	// A vein layer may not loosen the sandbox, and the call's own settings win.
+	got, warnings = applyVeinDefaults(runSettings{platform: "linux/arm64"}, &vein.Vein{Name: "python", Network: "host", Platform: "linux/amd64"})
+	if got.network != "" || got.platform != "linux/arm64" {
+		t.Errorf("settings = %+v", got)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "network=host") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestSupportedLanguages(t *testing.T) {
+	registry := vein.NewVeinRegistry()
+	for _, v := range []*vein.Vein{
+		{Name: "ruby", Container: "100hellos/ruby:latest"},
+		{Name: "python", Container: "100hellos/python:latest", Description: "Python 3 with numpy", Modes: []string{"main", "repl"}},
+		{Name: "c", Container: "100hellos/c:latest", Modes: []string{"main"}},
+	} {
+		if err := registry.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "c (modes: main), python (Python 3 with numpy; modes: main, repl), ruby"
+	if got := supportedLanguages(registry); got != want {
+		t.Errorf("supportedLanguages() = %q, want %q", got, want)
+	}
+}
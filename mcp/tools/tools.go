@@ -1,9 +1,12 @@
 package tools
 
 import (
+	"fmt"
+	"os"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ofthemachine/fraglet/pkg/runner"
 )
 
 var Server *mcp.Server
@@ -11,8 +14,28 @@ var Server *mcp.Server
 var (
 	runSavePath   string
 	runSavePathMu sync.RWMutex
+
+	runnerName   string
+	runnerNameMu sync.RWMutex
+
+	runLimits   = DefaultRunLimits
+	runLimitsMu sync.RWMutex
+
+	poolOpts runner.PoolOptions
+	pool     *runner.Pool
+	poolDown bool
+	poolMu   sync.Mutex
 )
 
+// DefaultRunLimits are the ceilings applied to every run tool call unless SetRunLimits replaces them
+// (e.g. fragletc mcp --memory=2g). Vein defaults and per-call input may lower them but never raise them.
+var DefaultRunLimits = runner.ResourceLimits{
+	Memory:    "1g",
+	CPUs:      "2",
+	PidsLimit: 512,
+	MaxOutput: "1m",
+}
+
 // SetRunSavePath sets the directory path for persisting successfully run fraglets.
 // When non-empty, the MCP run tool will save artifacts (content-addressed) after successful runs.
 // Must be called before Server.Run if persistence is desired (e.g. from fragletc mcp --save=/path).
@@ -28,6 +51,93 @@ func getRunSavePath() string {
 	return runSavePath
 }
 
+// SetRunner selects the runner backend ("docker", "podman") used by the run tool.
+// Empty restores automatic selection. Returns an error for unknown backend names.
+// Must be called before Server.Run (e.g. from fragletc mcp --runner=podman).
+func SetRunner(name string) error {
+	if name != "" {
+		if _, err := runner.ForName(name); err != nil {
+			return err
+		}
+	}
+	runnerNameMu.Lock()
+	defer runnerNameMu.Unlock()
+	runnerName = name
+	return nil
+}
+
+// SetRunLimits replaces the resource ceilings for the run tool (e.g. from fragletc mcp --memory=2g).
+// Unset fields remove that ceiling. Must be called before Server.Run.
+func SetRunLimits(limits runner.ResourceLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	runLimitsMu.Lock()
+	defer runLimitsMu.Unlock()
+	runLimits = limits
+	return nil
+}
+
+func getRunLimits() runner.ResourceLimits {
+	runLimitsMu.RLock()
+	defer runLimitsMu.RUnlock()
+	return runLimits
+}
+
+// SetPool enables a warm container pool for the run tool (e.g. from fragletc mcp --pool-size=2).
+// The pool is created on the first docker run; Shutdown removes its containers.
+// Must be called before Server.Run.
+func SetPool(opts runner.PoolOptions) error {
+	if opts.Size < 0 || opts.MaxTotal < 0 || opts.IdleTTL < 0 {
+		return fmt.Errorf("pool size, max and ttl must not be negative")
+	}
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	poolOpts = opts
+	return nil
+}
+
+// Shutdown releases resources held by the tools, removing any pooled containers.
+// Call it once Server.Run returns.
+func Shutdown() {
+	poolMu.Lock()
+	p := pool
+	pool, poolDown = nil, true
+	poolMu.Unlock()
+	if p != nil {
+		p.Close()
+	}
+}
+
+// newRunner returns the configured backend, or the FRAGLET_RUNNER/config/automatic choice for img.
+// Docker runs go through the warm pool when one is configured.
+func newRunner(img string) (runner.Runner, error) {
+	runnerNameMu.RLock()
+	name := runnerName
+	runnerNameMu.RUnlock()
+	r, err := runner.Select(name, img)
+	if err != nil || r.Name() != "docker" {
+		return r, err
+	}
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if poolOpts.Size == 0 || poolDown {
+		return r, nil
+	}
+	if pool == nil {
+		p, err := runner.NewPool(r, poolOpts)
+		if err != nil {
+			// Docker without the Engine API (CLI fallback): run cold.
+			fmt.Fprintf(os.Stderr, "fraglet: warm pool disabled: %v\n", err)
+			poolOpts.Size = 0
+			return r, nil
+		}
+		pool = p
+	}
+	return pool, nil
+}
+
 func init() {
 	Server = mcp.NewServer(
 		&mcp.Implementation{
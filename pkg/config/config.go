@@ -0,0 +1,58 @@
+// Package config loads the user's fragletc configuration file.
+//
+// The file lives at $FRAGLET_CONFIG, else $XDG_CONFIG_HOME/fraglet/config.yml, else
+// ~/.config/fraglet/config.yml. A missing file is the same as an empty one.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PathEnv overrides the config file location.
+const PathEnv = "FRAGLET_CONFIG"
+
+// Config is the contents of config.yml.
+type Config struct {
+	Runner  string            `yaml:"runner,omitempty"`  // default runner backend (docker, podman)
+	Aliases map[string]string `yaml:"aliases,omitempty"` // extra vein names, e.g. {py: python}
+}
+
+// Path returns the config file location (which may not exist).
+func Path() string {
+	if p := os.Getenv(PathEnv); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "fraglet", "config.yml")
+}
+
+// Load reads the config file. A missing file yields an empty Config.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	path := Path()
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
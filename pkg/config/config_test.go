@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv(PathEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got := Path(); got != "/xdg/fraglet/config.yml" {
+		t.Errorf("Path() = %q", got)
+	}
+	t.Setenv(PathEnv, "/etc/fraglet.yml")
+	if got := Path(); got != "/etc/fraglet.yml" {
+		t.Errorf("Path() with %s = %q", PathEnv, got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	t.Setenv(PathEnv, path)
+
+	cfg, err := Load()
+	if err != nil || cfg.Runner != "" {
+		t.Fatalf("missing file: cfg = %+v, err = %v", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte("runner: podman\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load()
+	if err != nil || cfg.Runner != "podman" {
+		t.Fatalf("cfg = %+v, err = %v", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte("runner: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,415 @@
+// Package dockerapi is a minimal Docker Engine API client covering what fraglet needs to run
+// containers without forking the docker CLI: ping, image inspect/pull, and the container
+// create → attach → start → wait → kill/stop → inspect → remove lifecycle, plus exec and archive
+// upload for reusing pre-started containers.
+package dockerapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HostEnvVar selects the daemon endpoint, as with the docker CLI.
+	HostEnvVar = "DOCKER_HOST"
+	// DefaultHost is used when DOCKER_HOST is unset.
+	DefaultHost = "unix:///var/run/docker.sock"
+	// APIVersion is the Engine API version requested (Docker 20.10+, Podman's compat API).
+	APIVersion = "v1.41"
+)
+
+// ErrNotFound is returned when the daemon answers 404 (no such image or container).
+var ErrNotFound = errors.New("not found")
+
+// Client talks to a Docker Engine API endpoint over a unix socket or plain TCP.
+// A Client is safe for concurrent use; reuse one across calls so connections are pooled.
+type Client struct {
+	host string // original endpoint, e.g. unix:///var/run/docker.sock
+	dial func(ctx context.Context) (net.Conn, error)
+	http *http.Client
+}
+
+// NewClient returns a client for host ("unix:///path" or "tcp://host:port").
+// TLS-protected and ssh:// endpoints are not supported; callers fall back to the docker CLI for those.
+func NewClient(host string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+	var network, address string
+	switch u.Scheme {
+	case "unix":
+		network, address = "unix", u.Path
+	case "tcp", "http":
+		network, address = "tcp", u.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q (use unix:// or tcp://)", u.Scheme)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("invalid docker host %q: missing address", host)
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+		MaxIdleConns:    4,
+		IdleConnTimeout: 30 * time.Second,
+	}
+	return &Client{host: host, dial: dial, http: &http.Client{Transport: transport}}, nil
+}
+
+// FromEnv returns a client for the endpoint EnvHost selects (DOCKER_HOST, the current docker
+// context, or the default local socket). DOCKER_TLS_VERIFY endpoints are rejected (unsupported).
+func FromEnv() (*Client, error) {
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		return nil, fmt.Errorf("DOCKER_TLS_VERIFY is set; TLS endpoints are not supported by the API client")
+	}
+	return NewClient(EnvHost())
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultClient *Client
+)
+
+// Default returns a shared client for the environment's daemon, verified with a ping.
+// A successful client is cached for the life of the process; failures are retried on the next call.
+func Default(ctx context.Context) (*Client, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultClient != nil {
+		return defaultClient, nil
+	}
+	c, err := FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := c.Ping(pingCtx); err != nil {
+		return nil, err
+	}
+	defaultClient = c
+	return c, nil
+}
+
+// Host returns the endpoint this client was created for.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// APIError is a non-2xx daemon response.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("docker api: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Is lets errors.Is(err, ErrNotFound) match 404 responses.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	var rdr io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rdr = bytes.NewReader(data)
+	}
+	u := "http://docker/" + APIVersion + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rdr)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends the request and returns the response for 2xx statuses; other statuses become *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker api %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &msg) == nil && msg.Message != "" {
+		return &APIError{StatusCode: resp.StatusCode, Message: msg.Message}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+}
+
+// doJSON sends the request and decodes a JSON response into out (when non-nil).
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := c.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Ping checks that the daemon is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/_ping", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ImageInspect returns metadata for a local image; errors.Is(err, ErrNotFound) when absent.
+func (c *Client) ImageInspect(ctx context.Context, ref string) (ImageInfo, error) {
+	var info ImageInfo
+	err := c.doJSON(ctx, http.MethodGet, "/images/"+ref+"/json", nil, nil, &info)
+	return info, err
+}
+
+// ImagePull pulls ref for platform (empty = daemon default) and waits for completion.
+// Pull failures reported mid-stream (e.g. manifest unknown) are returned as errors.
+func (c *Client) ImagePull(ctx context.Context, ref, platform string) error {
+	name, tag := splitRef(ref)
+	q := url.Values{"fromImage": {name}}
+	if tag != "" {
+		q.Set("tag", tag)
+	}
+	if platform != "" {
+		q.Set("platform", platform)
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/images/create", q, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error       string `json:"error"`
+			ErrorDetail struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("reading pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		if msg.ErrorDetail.Message != "" {
+			return errors.New(msg.ErrorDetail.Message)
+		}
+	}
+}
+
+// splitRef splits "repo:tag" (or "repo@sha256:...") into the fromImage/tag pair the pull endpoint expects.
+func splitRef(ref string) (name, tag string) {
+	if at := strings.Index(ref, "@"); at >= 0 {
+		return ref[:at], ref[at+1:]
+	}
+	lastSlash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > lastSlash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, ""
+}
+
+// ContainerCreate creates a container and returns its ID. name and platform may be empty.
+func (c *Client) ContainerCreate(ctx context.Context, name, platform string, cfg ContainerConfig) (string, error) {
+	q := url.Values{}
+	if name != "" {
+		q.Set("name", name)
+	}
+	if platform != "" {
+		q.Set("platform", platform)
+	}
+	var out struct {
+		ID string `json:"Id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/containers/create", q, cfg, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+// ContainerStart starts a created container.
+func (c *Client) ContainerStart(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+}
+
+// ContainerWait registers a wait for the container's next exit and returns a function that blocks
+// until it happens. Call it before ContainerStart so a fast exit cannot be missed.
+func (c *Client) ContainerWait(ctx context.Context, id string) (func() (WaitResult, error), error) {
+	resp, err := c.do(ctx, http.MethodPost, "/containers/"+id+"/wait", url.Values{"condition": {"next-exit"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func() (WaitResult, error) {
+		defer resp.Body.Close()
+		var res WaitResult
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			return WaitResult{}, fmt.Errorf("waiting for container: %w", err)
+		}
+		return res, nil
+	}, nil
+}
+
+// ContainerInspect returns the container's state.
+func (c *Client) ContainerInspect(ctx context.Context, id string) (ContainerInfo, error) {
+	var info ContainerInfo
+	err := c.doJSON(ctx, http.MethodGet, "/containers/"+id+"/json", nil, nil, &info)
+	return info, err
+}
+
+// ContainerStop sends the container's stop signal and kills it if it is still running after grace.
+// Stopping an already stopped container is not an error.
+func (c *Client) ContainerStop(ctx context.Context, id string, grace time.Duration) error {
+	// t is whole seconds; round up so a sub-second grace is not a kill.
+	q := url.Values{"t": {strconv.Itoa(int((grace + time.Second - 1) / time.Second))}}
+	err := c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/stop", q, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	return err
+}
+
+// ContainerKill sends signal (a name like "SIGINT" or a number) to the container's main process.
+func (c *Client) ContainerKill(ctx context.Context, id, signal string) error {
+	return c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/kill", url.Values{"signal": {signal}}, nil, nil)
+}
+
+// ContainerResize sets the size of a TTY container's terminal; the container must be running.
+func (c *Client) ContainerResize(ctx context.Context, id string, rows, cols uint16) error {
+	q := url.Values{"h": {strconv.Itoa(int(rows))}, "w": {strconv.Itoa(int(cols))}}
+	return c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/resize", q, nil, nil)
+}
+
+// ContainerRemove deletes a container; force kills it first when running.
+func (c *Client) ContainerRemove(ctx context.Context, id string, force bool) error {
+	q := url.Values{}
+	if force {
+		q.Set("force", "1")
+	}
+	return c.doJSON(ctx, http.MethodDelete, "/containers/"+id, q, nil, nil)
+}
+
+// ContainerAttach attaches to a created container's streams over a hijacked connection.
+// Attach before ContainerStart so no output is lost. With stdin true, writes to the returned
+// stream reach the container's stdin; CloseWrite signals EOF.
+func (c *Client) ContainerAttach(ctx context.Context, id string, stdin bool) (*HijackedStream, error) {
+	q := url.Values{"stream": {"1"}, "stdout": {"1"}, "stderr": {"1"}}
+	if stdin {
+		q.Set("stdin", "1")
+	}
+	return c.hijack(ctx, http.MethodPost, "/containers/"+id+"/attach", q, nil)
+}
+
+// hijack sends an upgrade request on a dedicated connection and returns it as a raw stream.
+func (c *Client) hijack(ctx context.Context, method, path string, query url.Values, body any) (*HijackedStream, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("docker api %s: %w", path, err)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("docker api %s: %w", path, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("docker api %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		defer conn.Close()
+		return nil, decodeError(resp)
+	}
+	return &HijackedStream{conn: conn, reader: br}, nil
+}
+
+// HijackedStream is the raw bidirectional attach connection.
+type HijackedStream struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Read returns raw (multiplexed unless TTY) container output.
+func (s *HijackedStream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Write sends bytes to the container's stdin.
+func (s *HijackedStream) Write(p []byte) (int, error) {
+	return s.conn.Write(p)
+}
+
+// CloseWrite half-closes the connection so the container sees EOF on stdin.
+func (s *HijackedStream) CloseWrite() error {
+	if cw, ok := s.conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (s *HijackedStream) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,62 @@
+package dockerapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+	"github.com/ofthemachine/fraglet/pkg/dockerapi/dockerapitest"
+)
+
+func TestNewClient_Schemes(t *testing.T) {
+	for _, host := range []string{"unix:///var/run/docker.sock", "tcp://127.0.0.1:2375"} {
+		if _, err := dockerapi.NewClient(host); err != nil {
+			t.Errorf("NewClient(%q): %v", host, err)
+		}
+	}
+	for _, host := range []string{"ssh://user@host", "npipe:////./pipe/docker_engine", "unix://"} {
+		if _, err := dockerapi.NewClient(host); err == nil {
+			t.Errorf("NewClient(%q): expected error", host)
+		}
+	}
+}
+
+func TestClient_PingInspectPull(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	c := srv.Client(t)
+	ctx := context.Background()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	_, err := c.ImageInspect(ctx, "100hellos/python:latest")
+	if !errors.Is(err, dockerapi.ErrNotFound) {
+		t.Fatalf("ImageInspect missing image: err = %v, want ErrNotFound", err)
+	}
+	if err := c.ImagePull(ctx, "100hellos/python:latest", "linux/amd64"); err != nil {
+		t.Fatalf("ImagePull: %v", err)
+	}
+	if len(srv.Pulled) != 1 || srv.Pulled[0] != "100hellos/python:latest" {
+		t.Fatalf("pulled = %v", srv.Pulled)
+	}
+	if _, err := c.ImageInspect(ctx, "100hellos/python:latest"); err != nil {
+		t.Fatalf("ImageInspect after pull: %v", err)
+	}
+}
+
+func TestDemux(t *testing.T) {
+	var muxed bytes.Buffer
+	dockerapi.MuxWriter{W: &muxed, Stream: dockerapi.StreamStdout}.Write([]byte("out1 "))
+	dockerapi.MuxWriter{W: &muxed, Stream: dockerapi.StreamStderr}.Write([]byte("err"))
+	dockerapi.MuxWriter{W: &muxed, Stream: dockerapi.StreamStdout}.Write([]byte("out2"))
+
+	var stdout, stderr bytes.Buffer
+	if err := dockerapi.Demux(&muxed, &stdout, &stderr); err != nil {
+		t.Fatalf("Demux: %v", err)
+	}
+	if stdout.String() != "out1 out2" || stderr.String() != "err" {
+		t.Fatalf("stdout=%q stderr=%q", stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,89 @@
+package dockerapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// EnvHost returns the endpoint the docker CLI would use: DOCKER_HOST, else the endpoint of the
+// current docker context (DOCKER_CONTEXT or the config file's currentContext), else DefaultHost.
+func EnvHost() string {
+	if host := os.Getenv(HostEnvVar); host != "" {
+		return host
+	}
+	if host := contextHost(); host != "" {
+		return host
+	}
+	return DefaultHost
+}
+
+// IsLocalHost reports whether host is a daemon on this machine, which can bind-mount host paths:
+// a unix socket or a loopback TCP address. ssh:// and other TCP endpoints are remote.
+//
+// A socket is assumed to lead to a daemon sharing this filesystem, which is not so when fragletc
+// itself runs in a container with the host's docker.sock mounted (docker-out-of-docker): the
+// daemon resolves bind paths on its own host. Such setups need the copy transport.
+func IsLocalHost(host string) bool {
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "unix", "npipe":
+		return true
+	case "tcp", "http", "https":
+		name := u.Hostname()
+		if name == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(name)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+// contextHost returns the docker endpoint of the current non-default docker context, or "".
+func contextHost() string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	name := os.Getenv("DOCKER_CONTEXT")
+	if name == "" {
+		var cfg struct {
+			CurrentContext string `json:"currentContext"`
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+		if err != nil || json.Unmarshal(data, &cfg) != nil {
+			return ""
+		}
+		name = cfg.CurrentContext
+	}
+	if name == "" || name == "default" {
+		return ""
+	}
+	// Context metadata lives in a directory named after the digest of the context name.
+	sum := sha256.Sum256([]byte(name))
+	data, err := os.ReadFile(filepath.Join(dir, "contexts", "meta", hex.EncodeToString(sum[:]), "meta.json"))
+	if err != nil {
+		return ""
+	}
+	var meta struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}
+	if json.Unmarshal(data, &meta) != nil {
+		return ""
+	}
+	return meta.Endpoints["docker"].Host
+}
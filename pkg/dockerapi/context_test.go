@@ -0,0 +1,63 @@
+package dockerapi_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+)
+
+func TestIsLocalHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"unix:///var/run/docker.sock": true,
+		"tcp://127.0.0.1:2375":        true,
+		"tcp://localhost:2375":        true,
+		"tcp://[::1]:2375":            true,
+		"tcp://docker:2375":           false,
+		"tcp://10.0.0.5:2376":         false,
+		"ssh://user@build-host":       false,
+	} {
+		if got := dockerapi.IsLocalHost(host); got != want {
+			t.Errorf("IsLocalHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestEnvHost_DockerContext(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+
+	if got := dockerapi.EnvHost(); got != dockerapi.DefaultHost {
+		t.Errorf("no context: EnvHost() = %q, want default", got)
+	}
+
+	sum := sha256.Sum256([]byte("vm"))
+	meta := filepath.Join(dir, "contexts", "meta", hex.EncodeToString(sum[:]))
+	if err := os.MkdirAll(meta, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(meta, "meta.json"), []byte(`{"Name":"vm","Endpoints":{"docker":{"Host":"ssh://me@vm"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"vm"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := dockerapi.EnvHost(); got != "ssh://me@vm" {
+		t.Errorf("current context: EnvHost() = %q, want ssh://me@vm", got)
+	}
+
+	t.Setenv("DOCKER_CONTEXT", "default")
+	if got := dockerapi.EnvHost(); got != dockerapi.DefaultHost {
+		t.Errorf("DOCKER_CONTEXT=default: EnvHost() = %q, want default", got)
+	}
+
+	t.Setenv("DOCKER_HOST", "tcp://docker:2375")
+	if got := dockerapi.EnvHost(); got != "tcp://docker:2375" {
+		t.Errorf("DOCKER_HOST wins: EnvHost() = %q", got)
+	}
+}
@@ -0,0 +1,588 @@
+// Package dockerapitest provides an in-process fake Docker Engine API served on a unix socket,
+// for exercising the dockerapi client and API-backed runners without a daemon.
+package dockerapitest
+
+import (
+	"archive/tar"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+)
+
+// Program simulates the container's process: it reads stdin (nil unless attached with stdin)
+// and writes output; the return value is the exit code.
+type Program func(c *Container, stdin io.Reader, stdout, stderr io.Writer) int
+
+// ExecProgram simulates a command started with exec inside a running container.
+type ExecProgram func(c *Container, e *Exec, stdin io.Reader, stdout, stderr io.Writer) int
+
+// Exec is a fake exec instance.
+type Exec struct {
+	ID        string
+	Container *Container
+	Config    dockerapi.ExecConfig
+	ExitCode  int
+	Running   bool
+}
+
+// Container is a fake container record.
+type Container struct {
+	ID       string
+	Name     string
+	Platform string
+	Config   dockerapi.ContainerConfig
+	State    dockerapi.ContainerState
+
+	attach   net.Conn
+	reader   *bufio.Reader
+	started  bool
+	exited   chan struct{}
+	exitOnce sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	oom      atomic.Bool
+	signals  []string // signals sent with kill, guarded by Server.mu
+	sizes    []string // "rowsxcols" from resize, guarded by Server.mu
+
+	filesMu sync.Mutex
+	files   map[string][]byte
+}
+
+// File returns a regular file copied into the container with the archive endpoint.
+func (c *Container) File(path string) ([]byte, bool) {
+	c.filesMu.Lock()
+	defer c.filesMu.Unlock()
+	data, ok := c.files[path]
+	return data, ok
+}
+
+// OOMKill makes the container report State.OOMKilled once it exits; the Program should then
+// return 137 as the kernel's SIGKILL would.
+func (c *Container) OOMKill() {
+	c.oom.Store(true)
+}
+
+// Stopping is closed when the container is asked to stop or is killed; long-running Programs
+// should return once it fires (like a process handling SIGTERM). Programs that ignore a stop
+// are killed when the stop grace period expires.
+func (c *Container) Stopping() <-chan struct{} {
+	return c.stop
+}
+
+// Server is a fake daemon. Set Program before running containers; Images seeds local images.
+type Server struct {
+	Socket string
+
+	mu         sync.Mutex
+	Program    Program
+	Exec       ExecProgram
+	Execs      map[string]*Exec
+	Images     map[string]dockerapi.ImageInfo
+	Pulled     []string // refs pulled (fromImage[:tag])
+	PullError  string   // if set, pulls fail with this error in the progress stream
+	Containers map[string]*Container
+	Requests   []string // "METHOD /path" in arrival order, version prefix stripped
+	nextID     int
+	listener   net.Listener
+}
+
+// NewServer starts a fake daemon on a temp unix socket; it is shut down with the test.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "dapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		Socket:     sock,
+		Images:     map[string]dockerapi.ImageInfo{},
+		Containers: map[string]*Container{},
+		Execs:      map[string]*Exec{},
+		listener:   l,
+		Program: func(c *Container, stdin io.Reader, stdout, stderr io.Writer) int {
+			return 0
+		},
+		Exec: func(c *Container, e *Exec, stdin io.Reader, stdout, stderr io.Writer) int {
+			return 0
+		},
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(s.serve)}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return s
+}
+
+// Host returns the DOCKER_HOST value for this server.
+func (s *Server) Host() string {
+	return "unix://" + s.Socket
+}
+
+// Client returns a dockerapi client connected to this server.
+func (s *Server) Client(t testing.TB) *dockerapi.Client {
+	t.Helper()
+	c, err := dockerapi.NewClient(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// RequestLog returns a copy of the request log.
+func (s *Server) RequestLog() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.Requests...)
+}
+
+// ContainerList returns all containers that have not been removed.
+func (s *Server) ContainerList() []*Container {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*Container
+	for _, c := range s.Containers {
+		out = append(out, c)
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter, what string) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such " + what})
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/v1.") {
+		if i := strings.Index(path[1:], "/"); i >= 0 {
+			path = path[i+1:]
+		}
+	}
+	s.mu.Lock()
+	s.Requests = append(s.Requests, r.Method+" "+path)
+	s.mu.Unlock()
+
+	switch {
+	case path == "/_ping":
+		w.Write([]byte("OK"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/json"):
+		s.imageInspect(w, strings.TrimSuffix(strings.TrimPrefix(path, "/images/"), "/json"))
+	case r.Method == http.MethodPost && path == "/images/create":
+		s.imagePull(w, r)
+	case r.Method == http.MethodPost && path == "/containers/create":
+		s.containerCreate(w, r)
+	case strings.HasPrefix(path, "/exec/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(path, "/exec/"), "/")
+		s.mu.Lock()
+		e := s.Execs[id]
+		s.mu.Unlock()
+		if e == nil {
+			notFound(w, "exec instance: "+id)
+			return
+		}
+		switch action {
+		case "start":
+			s.execStart(w, e)
+		case "json":
+			s.mu.Lock()
+			info := dockerapi.ExecInfo{ID: e.ID, Running: e.Running, ExitCode: e.ExitCode}
+			s.mu.Unlock()
+			writeJSON(w, http.StatusOK, info)
+		default:
+			notFound(w, "endpoint: "+action)
+		}
+	case strings.HasPrefix(path, "/containers/"):
+		rest := strings.TrimPrefix(path, "/containers/")
+		id, action, _ := strings.Cut(rest, "/")
+		s.mu.Lock()
+		c := s.lookup(id)
+		s.mu.Unlock()
+		if c == nil {
+			notFound(w, "container: "+id)
+			return
+		}
+		switch {
+		case r.Method == http.MethodDelete && action == "":
+			s.mu.Lock()
+			running := c.State.Running
+			s.mu.Unlock()
+			if running {
+				if r.URL.Query().Get("force") == "" {
+					writeJSON(w, http.StatusConflict, map[string]string{"message": "container is running"})
+					return
+				}
+				s.finish(c, 137)
+			}
+			s.mu.Lock()
+			delete(s.Containers, c.ID)
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		case action == "stop":
+			s.containerStop(w, r, c)
+		case r.Method == http.MethodPost && action == "kill":
+			s.containerKill(w, r, c)
+		case r.Method == http.MethodPost && action == "resize":
+			s.mu.Lock()
+			c.sizes = append(c.sizes, r.URL.Query().Get("h")+"x"+r.URL.Query().Get("w"))
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && action == "archive":
+			s.containerArchive(w, r, c)
+		case r.Method == http.MethodPost && action == "exec":
+			s.execCreate(w, r, c)
+		case action == "json":
+			s.mu.Lock()
+			info := dockerapi.ContainerInfo{ID: c.ID, Name: "/" + c.Name, State: c.State}
+			s.mu.Unlock()
+			writeJSON(w, http.StatusOK, info)
+		case action == "attach":
+			s.containerAttach(w, c)
+		case action == "start":
+			s.containerStart(w, c)
+		case action == "wait":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case <-c.exited:
+			case <-r.Context().Done():
+				return
+			}
+			s.mu.Lock()
+			code := c.State.ExitCode
+			s.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(dockerapi.WaitResult{StatusCode: code})
+		default:
+			notFound(w, "endpoint: "+action)
+		}
+	default:
+		notFound(w, "endpoint: "+path)
+	}
+}
+
+// lookup finds a container by ID or name; callers hold s.mu.
+func (s *Server) lookup(idOrName string) *Container {
+	if c, ok := s.Containers[idOrName]; ok {
+		return c
+	}
+	for _, c := range s.Containers {
+		if c.Name == idOrName {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) imageInspect(w http.ResponseWriter, ref string) {
+	s.mu.Lock()
+	info, ok := s.Images[ref]
+	s.mu.Unlock()
+	if !ok {
+		notFound(w, "image: "+ref)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) imagePull(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("fromImage")
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		if strings.HasPrefix(tag, "sha256:") {
+			ref += "@" + tag
+		} else {
+			ref += ":" + tag
+		}
+	}
+	s.mu.Lock()
+	if s.PullError != "" {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"error": s.PullError})
+		return
+	}
+	s.Pulled = append(s.Pulled, ref)
+	s.Images[ref] = dockerapi.ImageInfo{ID: "sha256:" + ref, RepoTags: []string{ref}}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{"status": "Downloaded newer image for " + ref})
+}
+
+func (s *Server) containerCreate(w http.ResponseWriter, r *http.Request) {
+	var cfg dockerapi.ContainerConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Images[cfg.Image]; !ok {
+		notFound(w, "image: "+cfg.Image)
+		return
+	}
+	s.nextID++
+	id := fmt.Sprintf("c%04d", s.nextID)
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = id
+	}
+	if s.lookup(name) != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "name already in use: " + name})
+		return
+	}
+	s.Containers[id] = &Container{
+		ID:       id,
+		Name:     name,
+		Platform: r.URL.Query().Get("platform"),
+		Config:   cfg,
+		State:    dockerapi.ContainerState{Status: "created"},
+		exited:   make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": id})
+}
+
+func (s *Server) containerAttach(w http.ResponseWriter, c *Container) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijack unsupported", http.StatusInternalServerError)
+		return
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	fmt.Fprint(conn, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	s.mu.Lock()
+	c.attach = conn
+	c.reader = brw.Reader
+	s.mu.Unlock()
+}
+
+// lockedWriter serializes frame writes from stdout and stderr onto one connection.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func (s *Server) containerStart(w http.ResponseWriter, c *Container) {
+	s.mu.Lock()
+	if c.started {
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	c.started = true
+	c.State.Status = "running"
+	c.State.Running = true
+	conn, reader, program := c.attach, c.reader, s.Program
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+
+	go func() {
+		var stdin io.Reader
+		var stdout, stderr io.Writer = io.Discard, io.Discard
+		if conn != nil {
+			if c.Config.OpenStdin {
+				stdin = reader
+			}
+			var mu sync.Mutex
+			if c.Config.Tty {
+				stdout = lockedWriter{&mu, conn}
+				stderr = stdout
+			} else {
+				stdout = lockedWriter{&mu, dockerapi.MuxWriter{W: conn, Stream: dockerapi.StreamStdout}}
+				stderr = lockedWriter{&mu, dockerapi.MuxWriter{W: conn, Stream: dockerapi.StreamStderr}}
+			}
+		}
+		s.finish(c, program(c, stdin, stdout, stderr))
+	}()
+}
+
+func (s *Server) containerArchive(w http.ResponseWriter, r *http.Request, c *Container) {
+	dir := r.URL.Query().Get("path")
+	tr := tar.NewReader(r.Body)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		c.filesMu.Lock()
+		if c.files == nil {
+			c.files = map[string][]byte{}
+		}
+		c.files[path.Join(dir, hdr.Name)] = data
+		c.filesMu.Unlock()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) execCreate(w http.ResponseWriter, r *http.Request, c *Container) {
+	var cfg dockerapi.ExecConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !c.State.Running {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "container " + c.ID + " is not running"})
+		return
+	}
+	s.nextID++
+	id := fmt.Sprintf("e%04d", s.nextID)
+	s.Execs[id] = &Exec{ID: id, Container: c, Config: cfg}
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": id})
+}
+
+// execStart hijacks the connection and runs Exec synchronously; the stream closes when it returns
+// or when the container dies.
+func (s *Server) execStart(w http.ResponseWriter, e *Exec) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijack unsupported", http.StatusInternalServerError)
+		return
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	fmt.Fprint(conn, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	s.mu.Lock()
+	e.Running = true
+	program := s.Exec
+	s.mu.Unlock()
+
+	var stdin io.Reader
+	if e.Config.AttachStdin {
+		stdin = brw.Reader
+	}
+	var mu sync.Mutex
+	stdout := lockedWriter{&mu, dockerapi.MuxWriter{W: conn, Stream: dockerapi.StreamStdout}}
+	stderr := lockedWriter{&mu, dockerapi.MuxWriter{W: conn, Stream: dockerapi.StreamStderr}}
+	done := make(chan int, 1)
+	go func() { done <- program(e.Container, e, stdin, stdout, stderr) }()
+	code := 137
+	select {
+	case code = <-done:
+	case <-e.Container.exited:
+	}
+	s.mu.Lock()
+	e.Running = false
+	e.ExitCode = code
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// finish records the container's exit once; a kill followed by the Program returning keeps the kill's code.
+func (s *Server) finish(c *Container, code int) {
+	c.exitOnce.Do(func() {
+		s.mu.Lock()
+		conn := c.attach
+		c.State.Status = "exited"
+		c.State.Running = false
+		c.State.ExitCode = code
+		c.State.OOMKilled = c.oom.Load()
+		s.mu.Unlock()
+		if conn != nil {
+			conn.Close()
+		}
+		close(c.exited)
+		c.stopOnce.Do(func() { close(c.stop) })
+	})
+}
+
+// Signals returns the signals sent to the container with kill, in order.
+func (s *Server) Signals(c *Container) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), c.signals...)
+}
+
+// Sizes returns the terminal sizes set with resize, as "rowsxcols", in order.
+func (s *Server) Sizes(c *Container) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), c.sizes...)
+}
+
+// containerKill records the signal; KILL ends the container (exit 137), anything else closes
+// Stopping for the Program to handle.
+func (s *Server) containerKill(w http.ResponseWriter, r *http.Request, c *Container) {
+	sig := r.URL.Query().Get("signal")
+	s.mu.Lock()
+	running := c.State.Running
+	if running {
+		c.signals = append(c.signals, sig)
+	}
+	s.mu.Unlock()
+	if !running {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "container " + c.ID + " is not running"})
+		return
+	}
+	switch sig {
+	case "9", "KILL", "SIGKILL":
+		s.finish(c, 137)
+	default:
+		c.stopOnce.Do(func() { close(c.stop) })
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// containerStop signals Stopping, then kills the container (exit 137) if it outlives ?t= seconds.
+func (s *Server) containerStop(w http.ResponseWriter, r *http.Request, c *Container) {
+	s.mu.Lock()
+	running := c.State.Running
+	s.mu.Unlock()
+	if !running {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	grace, _ := strconv.Atoi(r.URL.Query().Get("t"))
+	c.stopOnce.Do(func() { close(c.stop) })
+	select {
+	case <-c.exited:
+	case <-time.After(time.Duration(grace) * time.Second):
+		s.finish(c, 137)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
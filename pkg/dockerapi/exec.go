@@ -0,0 +1,71 @@
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ExecConfig is the body of POST /containers/{id}/exec.
+type ExecConfig struct {
+	Cmd          []string `json:"Cmd"`
+	Env          []string `json:"Env,omitempty"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+}
+
+// ExecInfo is the subset of GET /exec/{id}/json fraglet reads.
+type ExecInfo struct {
+	ID       string `json:"ID"`
+	Running  bool   `json:"Running"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// ExecCreate prepares a command to run inside a running container and returns the exec ID.
+func (c *Client) ExecCreate(ctx context.Context, id string, cfg ExecConfig) (string, error) {
+	var out struct {
+		ID string `json:"Id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/exec", nil, cfg, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+// ExecStart starts an exec and returns its attached streams (multiplexed unless Tty).
+// The stream ends when the command exits; ExecInspect then reports the exit code.
+func (c *Client) ExecStart(ctx context.Context, execID string) (*HijackedStream, error) {
+	body := map[string]bool{"Detach": false, "Tty": false}
+	return c.hijack(ctx, http.MethodPost, "/exec/"+execID+"/start", nil, body)
+}
+
+// ExecInspect returns the exec's state and exit code.
+func (c *Client) ExecInspect(ctx context.Context, execID string) (ExecInfo, error) {
+	var info ExecInfo
+	err := c.doJSON(ctx, http.MethodGet, "/exec/"+execID+"/json", nil, nil, &info)
+	return info, err
+}
+
+// CopyToContainer extracts the tar archive into the container's filesystem at dir.
+func (c *Client) CopyToContainer(ctx context.Context, id, dir string, archive io.Reader) error {
+	req, err := c.newRequest(ctx, http.MethodPut, "/containers/"+id+"/archive", url.Values{"path": {dir}}, nil)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(archive)
+	req.Header.Set("Content-Type", "application/x-tar")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker api PUT archive: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package dockerapi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Stream identifiers in the multiplexed attach protocol.
+const (
+	StreamStdin  = 0
+	StreamStdout = 1
+	StreamStderr = 2
+)
+
+// Demux copies a multiplexed (non-TTY) attach stream to stdout and stderr until EOF.
+// Each frame is an 8-byte header [stream, 0, 0, 0, size(uint32 BE)] followed by size bytes.
+func Demux(r io.Reader, stdout, stderr io.Writer) error {
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		var dst io.Writer
+		switch header[0] {
+		case StreamStdin, StreamStdout:
+			dst = stdout
+		case StreamStderr:
+			dst = stderr
+		default:
+			return fmt.Errorf("unknown stream id %d in attach frame", header[0])
+		}
+		if _, err := io.CopyN(dst, r, size); err != nil {
+			return err
+		}
+	}
+}
+
+// MuxWriter frames writes for one stream of the multiplexed attach protocol.
+// Used by test servers that emulate the daemon.
+type MuxWriter struct {
+	W      io.Writer
+	Stream byte
+}
+
+func (m MuxWriter) Write(p []byte) (int, error) {
+	var header [8]byte
+	header[0] = m.Stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(p)))
+	if _, err := m.W.Write(header[:]); err != nil {
+		return 0, err
+	}
+	return m.W.Write(p)
+}
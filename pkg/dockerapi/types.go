@@ -0,0 +1,76 @@
+package dockerapi
+
+// ContainerConfig is the body of POST /containers/create (the subset fraglet uses).
+type ContainerConfig struct {
+	Image        string     `json:"Image"`
+	Cmd          []string   `json:"Cmd,omitempty"`
+	Entrypoint   []string   `json:"Entrypoint,omitempty"`
+	Env          []string   `json:"Env,omitempty"`
+	WorkingDir   string     `json:"WorkingDir,omitempty"`
+	AttachStdin  bool       `json:"AttachStdin"`
+	AttachStdout bool       `json:"AttachStdout"`
+	AttachStderr bool       `json:"AttachStderr"`
+	OpenStdin    bool       `json:"OpenStdin"`
+	StdinOnce    bool       `json:"StdinOnce"`
+	Tty          bool       `json:"Tty"`
+	HostConfig   HostConfig `json:"HostConfig"`
+}
+
+// HostConfig carries mounts, networking and hardening options.
+type HostConfig struct {
+	Binds       []string `json:"Binds,omitempty"`
+	NetworkMode string   `json:"NetworkMode,omitempty"`
+	CapDrop     []string `json:"CapDrop,omitempty"`
+	SecurityOpt []string `json:"SecurityOpt,omitempty"`
+	AutoRemove  bool     `json:"AutoRemove,omitempty"`
+	Memory      int64    `json:"Memory,omitempty"`    // bytes
+	NanoCpus    int64    `json:"NanoCpus,omitempty"`  // CPU quota in units of 1e-9 CPUs
+	PidsLimit   *int64   `json:"PidsLimit,omitempty"` // nil = daemon default
+	Ulimits     []Ulimit `json:"Ulimits,omitempty"`
+}
+
+// Ulimit is one HostConfig.Ulimits entry.
+type Ulimit struct {
+	Name string `json:"Name"`
+	Soft int64  `json:"Soft"`
+	Hard int64  `json:"Hard"`
+}
+
+// ImageInfo is the subset of GET /images/{name}/json fraglet reads.
+type ImageInfo struct {
+	ID          string       `json:"Id"`
+	RepoTags    []string     `json:"RepoTags"`
+	RepoDigests []string     `json:"RepoDigests"`
+	Config      *ImageConfig `json:"Config,omitempty"`
+}
+
+// ImageConfig is the image's default process configuration.
+type ImageConfig struct {
+	Entrypoint []string `json:"Entrypoint"`
+	Cmd        []string `json:"Cmd"`
+	WorkingDir string   `json:"WorkingDir"`
+}
+
+// WaitResult is the body of POST /containers/{id}/wait.
+type WaitResult struct {
+	StatusCode int `json:"StatusCode"`
+	Error      *struct {
+		Message string `json:"Message"`
+	} `json:"Error,omitempty"`
+}
+
+// ContainerState is the State object from container inspect.
+type ContainerState struct {
+	Status    string `json:"Status"`
+	Running   bool   `json:"Running"`
+	OOMKilled bool   `json:"OOMKilled"`
+	ExitCode  int    `json:"ExitCode"`
+	Error     string `json:"Error"`
+}
+
+// ContainerInfo is the subset of GET /containers/{id}/json fraglet reads.
+type ContainerInfo struct {
+	ID    string         `json:"Id"`
+	Name  string         `json:"Name"`
+	State ContainerState `json:"State"`
+}
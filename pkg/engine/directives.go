@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+// applyDirectives fills what the command line and shebang left unset from the fraglet's
+// fraglet-meta directives. A declared mode belongs to the declared vein, so it is dropped when
+// the flags chose a different target. Declared env entries come first so -e flags override them.
+func applyDirectives(opts RunOptions, d fraglet.Directives) RunOptions {
+	metaVein, _, _ := parseVeinSpec(d.Vein)
+	flagVein, _, _ := parseVeinSpec(opts.VeinSpec)
+	targetFromMeta := false
+	if opts.VeinSpec == "" && opts.Image == "" && d.Vein != "" {
+		opts.VeinSpec, targetFromMeta = d.Vein, true
+		if opts.Mode != "" {
+			opts.VeinSpec = metaVein // --mode replaces a mode given as vein=name:mode
+		}
+	}
+	if _, specMode, _ := parseVeinSpec(opts.VeinSpec); opts.Mode == "" && specMode == "" {
+		if d.Vein == "" || targetFromMeta || flagVein == metaVein {
+			opts.Mode = d.Mode
+		}
+	}
+	if opts.NetworkMode == "" {
+		opts.NetworkMode = d.Network
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = d.Timeout
+	}
+	if opts.Platform == "" {
+		opts.Platform = d.Platform
+	}
+	if len(d.Env) > 0 {
+		opts.EnvFlags = append(slices.Clone(d.Env), opts.EnvFlags...)
+	}
+	return opts
+}
+
+// applyVeinDefaults fills the network and platform that neither flags nor fraglet-meta set from
+// the vein's veins.yml defaults.
+func applyVeinDefaults(opts RunOptions, v *vein.Vein) RunOptions {
+	if opts.NetworkMode == "" {
+		opts.NetworkMode = v.Network
+	}
+	if opts.Platform == "" {
+		opts.Platform = v.Platform
+	}
+	return opts
+}
+
+// Setting is one effective execution setting and where its value comes from: "command line",
+// "shebang", "fraglet-meta" or "vein" (veins.yml defaults).
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// EffectiveSettings reports the vein, image, mode, network, timeout, platform and env settings Run
+// would use for opts after applying the shebang, the fraglet-meta directives and the vein's
+// defaults, in that order.
+// Settings nobody set are omitted.
+func EffectiveSettings(opts RunOptions) ([]Setting, error) {
+	code, err := resolveCode(opts.InlineCode, opts.ScriptFile)
+	if err != nil {
+		return nil, err
+	}
+	d, err := fraglet.ParseDirectives(code)
+	if err != nil {
+		return nil, err
+	}
+	withShebang := applyShebang(opts)
+	withMeta := applyDirectives(withShebang, d)
+	final := withMeta
+	if name, _, err := parseVeinSpec(final.VeinSpec); err == nil && name != "" && final.Image == "" {
+		if registry, err := loadVeinRegistry(); err == nil {
+			if v, err := registry.Resolve(name); err == nil {
+				final = applyVeinDefaults(final, v)
+			}
+		}
+	}
+
+	var out []Setting
+	add := func(name string, get func(RunOptions) string) {
+		v := get(final)
+		switch {
+		case v == "":
+		case get(opts) == v:
+			out = append(out, Setting{name, v, "command line"})
+		case get(withShebang) == v:
+			out = append(out, Setting{name, v, "shebang"})
+		case get(withMeta) == v:
+			out = append(out, Setting{name, v, "fraglet-meta"})
+		default:
+			out = append(out, Setting{name, v, "vein"})
+		}
+	}
+	add("vein", func(o RunOptions) string { return o.VeinSpec })
+	add("image", func(o RunOptions) string { return o.Image })
+	add("mode", func(o RunOptions) string { return o.Mode })
+	add("network", func(o RunOptions) string { return o.NetworkMode })
+	add("timeout", func(o RunOptions) string {
+		if o.Timeout == 0 {
+			return ""
+		}
+		return o.Timeout.String()
+	})
+	add("platform", func(o RunOptions) string { return o.Platform })
+	for _, e := range final.EnvFlags {
+		source := "fraglet-meta"
+		if slices.Contains(opts.EnvFlags, e) {
+			source = "command line"
+		} else if slices.Contains(withShebang.EnvFlags, e) {
+			source = "shebang"
+		}
+		out = append(out, Setting{"env", e, source})
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/config"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+func TestEffectiveSettings_VeinDefaults(t *testing.T) {
+	dir := t.TempDir()
+	veins := filepath.Join(dir, "veins.yml")
+	if err := os.WriteFile(veins, []byte("veins:\n  - name: python\n    network: none\n    platform: linux/arm64\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.PathEnv, filepath.Join(dir, "config.yml"))
+	t.Setenv(vein.VeinsPathEnvVar, veins)
+
+	got, err := EffectiveSettings(RunOptions{
+		VeinSpec:   "python",
+		InlineCode: "# fraglet-meta: platform=linux/amd64\nprint(1)\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Setting{
+		{"vein", "python", "command line"},
+		{"network", "none", "vein"},
+		{"platform", "linux/amd64", "fraglet-meta"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("EffectiveSettings() = %v, want %v", got, want)
+	}
+}
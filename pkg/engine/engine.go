@@ -2,14 +2,21 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/ofthemachine/fraglet/pkg/embed"
 	"github.com/ofthemachine/fraglet/pkg/fraglet"
+	"github.com/ofthemachine/fraglet/pkg/redact"
 	"github.com/ofthemachine/fraglet/pkg/runner"
 	"github.com/ofthemachine/fraglet/pkg/vein"
 )
@@ -24,16 +31,37 @@ type RunOptions struct {
 	Mode        string
 	InlineCode  string
 	EnvFlags    []string
+	Secrets     []string // host env var names forwarded as secrets, or supplying the param of that name
 	ScriptFile  string
 	ScriptArgs  []string
 	Stdin       io.Reader
 	Stdout      io.Writer
 	Stderr      io.Writer
 	ParamStrs   []string
-	NetworkMode string // docker --network value (e.g. "none" to disable networking); empty = default
+	NetworkMode string                // docker --network value (e.g. "none" to disable networking); empty = default
+	Timeout     time.Duration         // maximum run time; 0 = no limit
+	Platform    string                // image platform (e.g. linux/arm64); empty = linux/amd64
+	Runner      string                // runner backend ("docker", "podman"); empty = FRAGLET_RUNNER, config, then automatic
+	Transport   string                // how code reaches the container ("auto", "bind", "copy"); empty = auto
+	Limits      runner.ResourceLimits // per-run limits; set fields override the vein's defaults
+	Verbose     bool                  // report runner, phase timings and termination reason on Stderr
+	// ForwardSignals traps SIGINT, SIGTERM and SIGHUP for the duration of the run and forwards them
+	// to the program, which is killed if it is still running after the stop grace period. Run then
+	// returns 128+signal, like a shell reporting a signalled child.
+	ForwardSignals bool
+	// Prompt asks on the terminal (Stdin, or os.Stdin when Stdin is nil, with prompts on Stderr) for
+	// each declared param without a value, instead of failing. Set it only when both are terminals.
+	Prompt bool
+	// TTY runs the program on a pseudo-terminal. When Stdin is a terminal it is put in raw mode
+	// for the run and window resizes are passed on; Stdin should then be that terminal (os.Stdin).
+	TTY bool
 }
 
-// Run orchestrates the execution of a fraglet
+// ForwardedSignals are the signals Run traps and forwards when ForwardSignals is set.
+var ForwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// Run orchestrates the execution of a fraglet. It returns the program's exit code, or on error
+// the status ExitCode assigns to it.
 func Run(ctx context.Context, opts RunOptions) (int, error) {
 	if opts.Stdout == nil {
 		opts.Stdout = os.Stdout
@@ -45,90 +73,275 @@ func Run(ctx context.Context, opts RunOptions) (int, error) {
 		opts.FragletPath = defaultFragletPath
 	}
 
+	// A fragletc shebang supplies defaults for flags not given, then the fraglet's own
+	// fraglet-meta directives for whatever is still unset. Code errors are reported after
+	// vein errors, as before directives existed.
+	opts = applyShebang(opts)
+	code, codeErr := resolveCode(opts.InlineCode, opts.ScriptFile)
+	if codeErr == nil {
+		d, err := fraglet.ParseDirectives(code)
+		if err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		}
+		for _, key := range d.Unknown {
+			fmt.Fprintf(opts.Stderr, "fragletc: warning: unknown fraglet-meta key %q ignored\n", key)
+		}
+		opts = applyDirectives(opts, d)
+	}
+
 	// --- Resolve vein + mode ---
 	veinName, finalMode, err := resolveVeinAndMode(opts.VeinSpec, opts.Mode, opts.Image, opts.ScriptFile)
 	if err != nil {
-		return 1, fmt.Errorf("Error: %w", err)
+		return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
 	}
 
 	// Validate mutual exclusion early
 	if opts.Image != "" && veinName != "" {
-		return 1, fmt.Errorf("Error: cannot specify both --image and --vein")
+		return ExitUsage, usageError{fmt.Errorf("Error: cannot specify both --image and --vein")}
 	}
 
 	// --- Resolve code ---
-	code, err := resolveCode(opts.InlineCode, opts.ScriptFile)
-	if err != nil {
-		return 1, fmt.Errorf("Error: %w", err)
+	if codeErr != nil {
+		return ExitUsage, usageError{fmt.Errorf("Error: %w", codeErr)}
 	}
 
 	// --- Resolve container + fraglet mount path ---
-	containerImage, fragletMountPath, err := resolveContainer(veinName, opts.Image, opts.FragletPath)
+	containerImage, fragletMountPath, v, err := resolveContainer(veinName, opts.Image, opts.FragletPath)
 	if err != nil {
-		return 1, fmt.Errorf("Error: %w", err)
+		return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+	}
+	var veinLimits runner.ResourceLimits
+	if v != nil {
+		if err := v.CheckMode(finalMode); err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		}
+		opts = applyVeinDefaults(opts, v)
+		veinLimits = v.Limits
+		if containerImage, err = lockedImage(v, containerImage, opts.Stderr); err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		}
+	}
+
+	transport, err := runner.ParseTransport(opts.Transport)
+	if err != nil {
+		return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
 	}
 
 	// --- Build env vars ---
 	envVars := buildEnvVars(finalMode, opts.EnvFlags)
 
 	// --- Parse and resolve params ---
-	if len(opts.ParamStrs) > 0 {
+	decls := fraglet.ParseParamDecls(code)
+	secretParams, secretVars, secretEnv, err := resolveSecrets(opts.Secrets, decls)
+	if err != nil {
+		return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+	}
+	masked := secretValues(nil, secretEnv)
+	var paramMounts []runner.VolumeMount
+	var fileValues bool // some value is passed as a file, over fraglet.LargeParamBytes
+	if len(opts.ParamStrs) > 0 || len(decls) > 0 {
 		var params fraglet.Params
 		for _, pf := range opts.ParamStrs {
 			p, err := fraglet.ParseParam(pf)
 			if err != nil {
-				return 1, fmt.Errorf("param error: %w", err)
+				return ExitUsage, usageError{fmt.Errorf("param error: %w", err)}
 			}
 			params = append(params, p)
 		}
 		// Resolve aliases via fraglet-meta declarations if code is available
-		decls := fraglet.ParseParamDecls(code)
 		if len(decls) > 0 {
 			var err error
 			params, err = params.ResolveAliases(decls)
 			if err != nil {
-				return 1, fmt.Errorf("param alias error: %w", err)
+				return ExitUsage, usageError{fmt.Errorf("param alias error: %w", err)}
+			}
+		}
+		// --secret supplies a param unless -p sets it too.
+		for _, sp := range secretParams {
+			if !slices.ContainsFunc(params, func(p fraglet.Param) bool { return p.EnvVar == sp.EnvVar }) {
+				params = append(params, sp)
+			}
+		}
+		// At a terminal, ask for missing params instead of failing.
+		if opts.Prompt {
+			in := opts.Stdin
+			if in == nil {
+				in = os.Stdin // a terminal stdin is not passed to the program without TTY
+			}
+			params, err = promptParams(params, decls, in, opts.Stderr)
+			if err != nil {
+				return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
 			}
 		}
-		transportEnv, err := params.ToTransportEnv()
+		// Required params must all be present before a container starts; defaults fill the rest.
+		params, err = params.ApplyDecls(decls)
+		if err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w (pass each with -p name=value; see --fraglet-help)", err)}
+		}
+		// Typed params are checked and normalized here, so the program gets canonical values.
+		params, err = params.Validate(decls)
+		if err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		}
+		// File-valued params (-p name=@path, type=file) are mounted read-only; the value becomes the container path.
+		params, mounts, err := params.FileMounts(decls)
+		if err != nil {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		}
+		// Secret params travel with the --secret variables: off every argv, masked in output.
+		// Values over fraglet.LargeParamBytes are passed as mounted files rather than env vars.
+		params, secrets := params.SplitSecrets(decls, secretVars)
+		transportEnv, valueMounts, cleanupValues, err := params.ToTransport()
+		if err != nil {
+			return ExitUsage, usageError{fmt.Errorf("param transport error: %w", err)}
+		}
+		defer cleanupValues()
+		secretTransportEnv, secretMounts, cleanupSecrets, err := secrets.ToTransport()
 		if err != nil {
-			return 1, fmt.Errorf("param transport error: %w", err)
+			return ExitUsage, usageError{fmt.Errorf("param transport error: %w", err)}
+		}
+		defer cleanupSecrets()
+		fileValues = len(valueMounts)+len(secretMounts) > 0
+		for _, m := range slices.Concat(mounts, valueMounts, secretMounts) {
+			paramMounts = append(paramMounts, runner.VolumeMount{HostPath: m.HostPath, ContainerPath: m.ContainerPath})
 		}
 		envVars = append(envVars, transportEnv...)
+		secretEnv = append(secretEnv, secretTransportEnv...)
+		masked = append(masked, secretValues(secrets, nil)...)
 	}
+	redactor := redact.New(masked...)
+	stdout, stderr := redactor.Writer(opts.Stdout), redactor.Writer(opts.Stderr)
 
 	// --- Write temp file, build spec, execute ---
+	// The runner mounts the file or, for a daemon that cannot see it, copies it in.
 	tmpFile, cleanup, err := writeTempFile(code)
 	if err != nil {
-		return 1, fmt.Errorf("error creating temp file: %w", err)
+		return ExitInternal, internalError{fmt.Errorf("error creating temp file: %w", err)}
 	}
 	defer cleanup()
 
-	r := runner.NewRunner(containerImage, "")
+	r, err := runner.Select(opts.Runner, containerImage)
+	if err != nil {
+		err = fmt.Errorf("Error: %w", err)
+		return ExitCode(err), err
+	}
 	spec := runner.RunSpec{
 		Container:   containerImage,
 		Env:         envVars,
+		SecretEnv:   secretEnv,
 		Args:        opts.ScriptArgs,
 		NetworkMode: opts.NetworkMode,
+		Platform:    opts.Platform,
+		Transport:   transport,
+		Limits:      veinLimits.Merge(opts.Limits),
 		StdinReader: opts.Stdin,
-		Stdout:      opts.Stdout,
-		Stderr:      opts.Stderr,
-		Volumes: []runner.VolumeMount{
+		Stdout:      stdout,
+		Stderr:      stderr,
+		Volumes: append([]runner.VolumeMount{
 			{
 				HostPath:      tmpFile,
 				ContainerPath: fragletMountPath,
 			},
-		},
+		}, paramMounts...),
+	}
+	if fileValues {
+		if err := CheckParamFiles(ctx, r, spec); errors.Is(err, ErrParamFilesUnsupported) {
+			return ExitUsage, usageError{fmt.Errorf("Error: %w", err)}
+		} else if err != nil {
+			err = fmt.Errorf("Error: %w", err)
+			return ExitCode(err), err
+		}
+	}
+
+	var received func() os.Signal
+	if opts.ForwardSignals {
+		var stop func()
+		spec.Signals, received, stop = trapSignals()
+		defer stop()
+	}
+
+	restoreTTY := func() {}
+	if opts.TTY {
+		spec.TTY = true
+		if spec.TermSizes, restoreTTY, err = startTTY(opts.Stdin, opts.Stdout); err != nil {
+			return ExitInternal, internalError{fmt.Errorf("Error: terminal: %w", err)}
+		}
+		defer restoreTTY()
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
 	result, err := r.Run(ctx, spec)
+	stdout.Flush()
+	stderr.Flush()
+	restoreTTY() // before anything else is printed
+	if opts.Verbose {
+		t := result.Timings
+		fmt.Fprintf(opts.Stderr, "fragletc: runner=%s image=%s pull=%s create=%s execute=%s termination=%s exit=%d\n",
+			r.Name(), containerImage, t.Pull.Round(time.Millisecond), t.Create.Round(time.Millisecond),
+			t.Execute.Round(time.Millisecond), result.Termination, result.ExitCode)
+	}
 	if err != nil {
-		return 1, fmt.Errorf("execution failed: %w", err)
+		err = fmt.Errorf("execution failed: %w", err)
+		return ExitCode(err), err
+	}
+	if result.Truncated {
+		fmt.Fprintf(opts.Stderr, "\nfragletc: output truncated at %s (--max-output)\n", spec.Limits.MaxOutput)
+	}
+	if received != nil {
+		if sig := received(); sig != nil {
+			if n, ok := sig.(syscall.Signal); ok {
+				return 128 + int(n), nil
+			}
+		}
 	}
 
 	return result.ExitCode, nil
 }
 
+// trapSignals relays ForwardedSignals to the returned channel instead of letting them terminate the
+// process. received reports the first one; stop restores default handling.
+func trapSignals() (relay <-chan os.Signal, received func() os.Signal, stop func()) {
+	incoming := make(chan os.Signal, 4)
+	out := make(chan os.Signal, 4)
+	done := make(chan struct{})
+	var mu sync.Mutex
+	var first os.Signal
+	signal.Notify(incoming, ForwardedSignals...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-incoming:
+				mu.Lock()
+				if first == nil {
+					first = sig
+				}
+				mu.Unlock()
+				select {
+				case out <- sig:
+				default: // runner is not keeping up; the escalation timer is already running
+				}
+			}
+		}
+	}()
+	received = func() os.Signal {
+		mu.Lock()
+		defer mu.Unlock()
+		return first
+	}
+	stop = func() {
+		signal.Stop(incoming)
+		close(done)
+	}
+	return out, received, stop
+}
+
 func resolveVeinAndMode(veinSpec, modeFlag, image, scriptFile string) (veinName, mode string, err error) {
 	if veinSpec != "" {
 		var parsedMode string
@@ -149,12 +362,18 @@ func resolveVeinAndMode(veinSpec, modeFlag, image, scriptFile string) (veinName,
 
 	mode = modeFlag
 
-	// Infer vein from file extension when no --vein and no --image
+	// Infer vein when no --vein and no --image: from an interpreter shebang naming a vein or one
+	// of its aliases (#!/usr/bin/env node), otherwise from the file extension
 	if scriptFile != "" && image == "" {
 		registry, err := loadVeinRegistry()
 		if err != nil {
 			return "", "", fmt.Errorf("error loading veins: %w", err)
 		}
+		if interpreter := shebangInterpreter(scriptFile); interpreter != "" {
+			if v, err := registry.Resolve(interpreter); err == nil {
+				return v.Name, mode, nil
+			}
+		}
 		extMap := vein.NewExtensionMap(registry)
 		veinName, err = extMap.VeinForFile(scriptFile)
 		if err != nil {
@@ -179,24 +398,26 @@ func resolveCode(inlineCode, scriptFile string) (string, error) {
 	return "", fmt.Errorf("no code source provided. Use a script file or -c flag")
 }
 
-func resolveContainer(veinName, image, fragletPath string) (containerImage, mountPath string, err error) {
+// resolveContainer returns the image to run and where the fraglet is mounted, and the vein
+// (nil for --image) whose defaults apply.
+func resolveContainer(veinName, image, fragletPath string) (containerImage, mountPath string, v *vein.Vein, err error) {
 	if veinName != "" {
 		registry, err := loadVeinRegistry()
 		if err != nil {
-			return "", "", fmt.Errorf("error loading veins: %w", err)
+			return "", "", nil, fmt.Errorf("error loading veins: %w", err)
 		}
-		v, ok := registry.Get(veinName)
-		if !ok {
-			return "", "", fmt.Errorf("vein not found: %s", veinName)
+		v, err := registry.Resolve(veinName)
+		if err != nil {
+			return "", "", nil, err
 		}
-		return v.ContainerImage(), defaultFragletPath, nil
+		return v.ContainerImage(), defaultFragletPath, v, nil
 	}
 
 	if image != "" {
-		return image, fragletPath, nil
+		return image, fragletPath, nil, nil
 	}
 
-	return "", "", fmt.Errorf("no container target. Specify --vein or --image")
+	return "", "", nil, fmt.Errorf("no container target. Specify --vein or --image")
 }
 
 func buildEnvVars(mode string, envFlags []string) []string {
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+)
+
+// ErrUsage marks errors in what was asked for (vein, mode, code source, params) as opposed to
+// failures running it; the runner's errors (runner.ErrImagePull, runner.ErrDaemon, ...) pass
+// through Run wrapped, so errors.Is works on either.
+var ErrUsage = errors.New("invalid invocation")
+
+// ErrInternal marks local failures on the host, such as writing the temporary fraglet file,
+// that are neither the caller's mistake nor the container engine's.
+var ErrInternal = errors.New("internal error")
+
+// Exit statuses fragletc uses when the program itself did not produce one. They follow the
+// timeout(1) and docker run conventions so scripts can tell them apart from the program's codes.
+const (
+	ExitUsage      = 1   // invalid input
+	ExitInternal   = 70  // a local failure on the host (sysexits EX_SOFTWARE)
+	ExitTimeout    = 124 // the run exceeded its deadline
+	ExitDaemon     = 125 // no usable runner, container engine failure or image pull failure
+	ExitEntrypoint = 126 // the container's entrypoint could not be run
+	ExitCancelled  = 130 // the run was interrupted (128 + SIGINT)
+)
+
+// ExitCode maps an error returned by Run to the status fragletc exits with.
+func ExitCode(err error) int {
+	switch {
+	case errors.Is(err, runner.ErrTimeout):
+		return ExitTimeout
+	case errors.Is(err, runner.ErrCancelled):
+		return ExitCancelled
+	case errors.Is(err, runner.ErrEntrypoint):
+		return ExitEntrypoint
+	case errors.Is(err, runner.ErrImagePull), errors.Is(err, runner.ErrDaemon):
+		return ExitDaemon
+	case errors.Is(err, ErrInternal):
+		return ExitInternal
+	}
+	return ExitUsage
+}
+
+// usageError tags err as ErrUsage without changing its message.
+type usageError struct{ err error }
+
+func (e usageError) Error() string   { return e.err.Error() }
+func (e usageError) Unwrap() []error { return []error{ErrUsage, e.err} }
+
+// internalError tags err as ErrInternal without changing its message.
+type internalError struct{ err error }
+
+func (e internalError) Error() string   { return e.err.Error() }
+func (e internalError) Unwrap() []error { return []error{ErrInternal, e.err} }
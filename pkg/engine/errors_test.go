@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{usageError{errors.New("bad vein")}, ExitUsage},
+		{internalError{errors.New("disk full")}, ExitInternal},
+		{fmt.Errorf("Error: %w", runner.ErrDaemon), ExitDaemon},
+		{fmt.Errorf("Error: %w", runner.ErrTimeout), ExitTimeout},
+	} {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+	if errors.Is(internalError{errors.New("disk full")}, ErrUsage) {
+		t.Error("internal error matches ErrUsage")
+	}
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ofthemachine/fraglet/pkg/lock"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+// lockedImage returns the digest that the nearest fraglet.lock (from the working directory up)
+// pins the vein to, or image when there is no lockfile or the vein is not locked. A pin made for
+// another container is reported and skipped. FRAGLET_VEINS_FORCE_TAG bypasses the lock.
+func lockedImage(v *vein.Vein, image string, stderr io.Writer) (string, error) {
+	if os.Getenv("FRAGLET_VEINS_FORCE_TAG") != "" {
+		return image, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return image, nil
+	}
+	path := lock.Find(wd)
+	if path == "" {
+		return image, nil
+	}
+	l, err := lock.Load(path)
+	if err != nil {
+		return "", err
+	}
+	digest, ok, stale := l.Pin(v)
+	if stale != nil {
+		fmt.Fprintf(stderr, "fragletc: warning: %s pins %s for %s, but the vein now uses %s; running unpinned (update it with fragletc lock %s)\n",
+			path, v.Name, stale.Container, v.Container, v.Name)
+	}
+	if !ok {
+		return image, nil
+	}
+	return digest, nil
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/lock"
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+func TestLockedImage(t *testing.T) {
+	dir := t.TempDir()
+	lockfile := "veins:\n  python:\n    container: 100hellos/python:latest\n    digest: 100hellos/python@sha256:aaaa\n" +
+		"  ruby:\n    container: 100hellos/ruby:3\n    digest: 100hellos/ruby@sha256:bbbb\n"
+	if err := os.WriteFile(filepath.Join(dir, lock.FileName), []byte(lockfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "src")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(sub); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("FRAGLET_VEINS_FORCE_TAG", "")
+
+	python := &vein.Vein{Name: "python", Container: "100hellos/python:latest"}
+	var stderr bytes.Buffer
+	if got, err := lockedImage(python, "100hellos/python:latest", &stderr); err != nil || got != "100hellos/python@sha256:aaaa" {
+		t.Errorf("lockedImage(python) = %q, %v", got, err)
+	}
+
+	ruby := &vein.Vein{Name: "ruby", Container: "100hellos/ruby:latest"}
+	if got, _ := lockedImage(ruby, "100hellos/ruby:latest", &stderr); got != "100hellos/ruby:latest" {
+		t.Errorf("lockedImage(stale ruby) = %q", got)
+	}
+	if !strings.Contains(stderr.String(), "pins ruby for 100hellos/ruby:3") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+
+	t.Setenv("FRAGLET_VEINS_FORCE_TAG", "dev")
+	if got, _ := lockedImage(python, "100hellos/python:dev", &stderr); got != "100hellos/python:dev" {
+		t.Errorf("lockedImage with FRAGLET_VEINS_FORCE_TAG = %q", got)
+	}
+}
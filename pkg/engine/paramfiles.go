@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+)
+
+// ErrParamFilesUnsupported marks an image whose fraglet-entrypoint predates v0.7.0 and so ignores
+// param values passed as files.
+var ErrParamFilesUnsupported = errors.New("fraglet-entrypoint cannot read param values over 64 KiB (needs v0.7.0 or later)")
+
+// paramFilesMarker is what the usage document of every entrypoint that reads params passed as
+// files mentions.
+const paramFilesMarker = "FRAGLET_PARAMFILE_"
+
+// CheckParamFiles verifies that spec's image runs a fraglet-entrypoint that reads param values
+// passed as files, the way fraglet.Params.ToTransport sends values over fraglet.LargeParamBytes.
+// It runs the entrypoint's usage command, which every entrypoint since v0.2.0 answers without
+// running any code, so call it only when such values are being sent. An older entrypoint yields
+// an error matching ErrParamFilesUnsupported rather than a run that silently lacks the params.
+func CheckParamFiles(ctx context.Context, r runner.Runner, spec runner.RunSpec) error {
+	result, err := r.Run(ctx, runner.RunSpec{
+		Container:   spec.Container,
+		Args:        []string{"usage"},
+		NetworkMode: spec.NetworkMode,
+		Platform:    spec.Platform,
+		Limits:      spec.Limits,
+	})
+	if err != nil {
+		return fmt.Errorf("checking the fraglet-entrypoint of %s: %w", spec.Container, err)
+	}
+	if !strings.Contains(result.Stdout, paramFilesMarker) {
+		return fmt.Errorf("image %s: %w; upgrade the image or pass a smaller value", spec.Container, ErrParamFilesUnsupported)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+)
+
+// usageRunner answers the entrypoint's usage command with usage and records the specs it ran.
+type usageRunner struct {
+	usage string
+	err   error
+	specs []runner.RunSpec
+}
+
+func (r *usageRunner) Run(ctx context.Context, spec runner.RunSpec) (runner.RunResult, error) {
+	r.specs = append(r.specs, spec)
+	return runner.RunResult{Stdout: r.usage}, r.err
+}
+
+func (r *usageRunner) RunStreaming(ctx context.Context, spec runner.RunSpec) (*runner.StreamingResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *usageRunner) Name() string    { return "usage" }
+func (r *usageRunner) Available() bool { return true }
+
+func TestCheckParamFiles(t *testing.T) {
+	spec := runner.RunSpec{
+		Container: "img",
+		Env:       []string{"FRAGLET_PARAMFILE_DOC=/fraglet-params/.values/DOC"},
+		Volumes:   []runner.VolumeMount{{HostPath: "/tmp/doc", ContainerPath: "/fraglet-params/.values/DOC"}},
+	}
+
+	current := &usageRunner{usage: "## Parameters\n`FRAGLET_PARAMFILE_<NAME>=<path>` names a mounted file\n"}
+	if err := CheckParamFiles(context.Background(), current, spec); err != nil {
+		t.Fatalf("current entrypoint: %v", err)
+	}
+	// Only the usage command runs: no code, params or mounts.
+	probe := current.specs[0]
+	if probe.Container != "img" || !slices.Equal(probe.Args, []string{"usage"}) || len(probe.Env) != 0 || len(probe.Volumes) != 0 {
+		t.Errorf("probe spec = %+v", probe)
+	}
+
+	old := &usageRunner{usage: "# Container Usage\n\n## Documentation\n"}
+	if err := CheckParamFiles(context.Background(), old, spec); !errors.Is(err, ErrParamFilesUnsupported) {
+		t.Errorf("old entrypoint: err = %v", err)
+	}
+
+	broken := &usageRunner{err: runner.ErrDaemon}
+	if err := CheckParamFiles(context.Background(), broken, spec); !errors.Is(err, runner.ErrDaemon) || errors.Is(err, ErrParamFilesUnsupported) {
+		t.Errorf("daemon failure: err = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+	"golang.org/x/term"
+)
+
+// errPromptCancelled is returned when the terminal is closed (Ctrl-D) during a prompt.
+var errPromptCancelled = errors.New("param prompt cancelled")
+
+// promptParams asks on the terminal for each declared param that has no value: each prompt shows
+// the param's description, type and default. Required params are asked again until answered; an
+// empty answer leaves an optional param to its default. Answers are checked against the declared
+// type before moving on, and secret params are read without echo. The answers join params as raw
+// values for the usual pipeline.
+func promptParams(params fraglet.Params, decls []fraglet.ParamDecl, in io.Reader, out io.Writer) (fraglet.Params, error) {
+	var unset []fraglet.ParamDecl
+	for _, d := range decls {
+		if !slices.ContainsFunc(params, func(p fraglet.Param) bool { return p.EnvVar == d.EnvVar }) {
+			unset = append(unset, d)
+		}
+	}
+	if len(unset) == 0 {
+		return params, nil
+	}
+
+	fmt.Fprintf(out, "This fraglet takes params (Ctrl-D to cancel):\n")
+	for _, d := range unset {
+		if about := d.Modifiers["description"]; about != "" {
+			fmt.Fprintf(out, "  %s\n", about)
+		}
+		for {
+			fmt.Fprintf(out, "%s: ", promptLabel(d))
+			answer, err := readAnswer(in, out, d.IsSecret())
+			if err != nil {
+				return nil, err
+			}
+			if answer == "" {
+				if d.IsRequired() {
+					fmt.Fprintf(out, "  a value is required\n")
+					continue
+				}
+				break // the default, if any, is applied with the other declarations
+			}
+			if _, err := d.Normalize(answer); err != nil {
+				fmt.Fprintf(out, "  %v\n", err)
+				continue
+			}
+			params = append(params, fraglet.Param{EnvVar: d.EnvVar, Encoding: "raw", Value: answer})
+			break
+		}
+	}
+	return params, nil
+}
+
+// promptLabel is "name (required, int) [3]": requirement, declared type and default.
+func promptLabel(d fraglet.ParamDecl) string {
+	parts := []string{"optional"}
+	if d.IsRequired() {
+		parts[0] = "required"
+	}
+	if _, typed := d.Modifiers["type"]; typed {
+		parts = append(parts, d.TypeLabel())
+	}
+	if d.IsSecret() {
+		parts = append(parts, "secret, hidden")
+	}
+	label := fmt.Sprintf("%s (%s)", d.Alias, strings.Join(parts, ", "))
+	if def, ok := d.Default(); ok {
+		label += " [" + def + "]"
+	}
+	return label
+}
+
+// readAnswer reads one line from in, a byte at a time: nothing past the line is consumed, so
+// secrets read from the terminal without echo and the program's stdin continue from the same place.
+func readAnswer(in io.Reader, out io.Writer, secret bool) (string, error) {
+	if f, ok := in.(*os.File); ok && secret && term.IsTerminal(int(f.Fd())) {
+		b, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(out)
+		if err != nil {
+			return "", errPromptCancelled
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	var line []byte
+	var b [1]byte
+	for {
+		n, err := in.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if len(line) == 0 || !errors.Is(err, io.EOF) {
+				fmt.Fprintln(out)
+				return "", errPromptCancelled
+			}
+			break
+		}
+	}
+	return strings.TrimSpace(string(line)), nil
+}
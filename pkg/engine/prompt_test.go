@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+)
+
+func TestPromptParams(t *testing.T) {
+	decls := fraglet.ParseParamDecls("# fraglet-meta: ---\n# params:\n" +
+		"#   city: {required: true, description: City to look up}\n" +
+		"#   days: {type: int, default: 3}\n" +
+		"#   units: {required: true, type: \"enum(metric,imperial)\"}\n" +
+		"# fraglet-meta: ---")
+	given := fraglet.Params{{EnvVar: "UNITS", Encoding: "raw", Value: "metric"}}
+	// city: empty then answered; days: invalid then left to its default.
+	var out strings.Builder
+	got, err := promptParams(given, decls, strings.NewReader("\nParis\nmany\n\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1] != (fraglet.Param{EnvVar: "CITY", Encoding: "raw", Value: "Paris"}) {
+		t.Errorf("params = %v", got.ToCanonical())
+	}
+	for _, want := range []string{"City to look up", "city (required): ", "a value is required",
+		"days (optional, int) [3]: ", `"many" is not an integer`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("prompt output lacks %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "units") {
+		t.Errorf("prompted for a param already given:\n%s", out.String())
+	}
+
+	// Only optional params are unset: they are asked for too.
+	out.Reset()
+	withCity := append(given, fraglet.Param{EnvVar: "CITY", Value: "Rome"})
+	got, err = promptParams(withCity, decls, strings.NewReader("5\n"), &out)
+	if err != nil || len(got) != 3 || got[2].Value != "5" || !strings.Contains(out.String(), "days (optional, int) [3]: ") {
+		t.Errorf("params = %v, err = %v, output = %q", got, err, out.String())
+	}
+
+	// Every param given: no prompt at all.
+	out.Reset()
+	got, err = promptParams(append(withCity, fraglet.Param{EnvVar: "DAYS", Value: "2"}), decls, strings.NewReader(""), &out)
+	if err != nil || len(got) != 3 || out.Len() != 0 {
+		t.Errorf("params = %v, err = %v, output = %q", got, err, out.String())
+	}
+
+	// Answers consume only their own lines; the rest of the input is left for the program.
+	rest := strings.NewReader("Oslo\n\nprogram input\n")
+	if _, err := promptParams(given, decls, rest, &out); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := io.ReadAll(rest); string(left) != "program input\n" {
+		t.Errorf("input left = %q", left)
+	}
+
+	// Closing the terminal cancels.
+	if _, err := promptParams(nil, decls, strings.NewReader(""), &out); !errors.Is(err, errPromptCancelled) {
+		t.Errorf("err = %v, want cancelled", err)
+	}
+}
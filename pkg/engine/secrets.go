@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+)
+
+// resolveSecrets reads each --secret name from the environment. A name matching a param
+// declaration (by alias or env var) supplies that param, whose env var is added to secretVars for
+// SplitSecrets; the others are forwarded as NAME=value. Secrets reach the runner in
+// RunSpec.SecretEnv, never in argv.
+func resolveSecrets(names []string, decls []fraglet.ParamDecl) (params fraglet.Params, secretVars map[string]bool, env []string, err error) {
+	secretVars = make(map[string]bool)
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("--secret %s: not set in the environment", name)
+		}
+		matched := false
+		for _, d := range decls {
+			if d.EnvVar == name || d.Alias == strings.ToLower(name) {
+				secretVars[d.EnvVar] = true
+				params = append(params, fraglet.Param{EnvVar: d.EnvVar, Encoding: "raw", Value: value})
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			env = append(env, name+"="+value)
+		}
+	}
+	return params, secretVars, env, nil
+}
+
+// secretValues returns the decoded values of params, the part of each NAME=value in env, for masking.
+func secretValues(params fraglet.Params, env []string) []string {
+	var values []string
+	for _, p := range params {
+		if v, err := p.Decode(); err == nil {
+			values = append(values, v)
+		}
+	}
+	for _, e := range env {
+		_, v, _ := strings.Cut(e, "=")
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/fraglet"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("API_TOKEN", "t0ken")
+	t.Setenv("OTHER", "x")
+	decls := fraglet.ParseParamDecls("# fraglet-meta: param=api_token param=city")
+	params, secretVars, env, err := resolveSecrets([]string{"API_TOKEN", "OTHER"}, decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 1 || params[0].EnvVar != "API_TOKEN" || !secretVars["API_TOKEN"] {
+		t.Errorf("params = %v, secretVars = %v", params, secretVars)
+	}
+	if len(env) != 1 || env[0] != "OTHER=x" {
+		t.Errorf("env = %v", env)
+	}
+	// The declarations are left as parsed.
+	if decls[0].IsSecret() {
+		t.Error("decls changed: api_token marked secret")
+	}
+	if _, _, _, err := resolveSecrets([]string{"FRAGLET_TEST_UNSET"}, decls); err == nil {
+		t.Error("unset secret: expected error")
+	}
+}
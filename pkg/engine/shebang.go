@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// shebangDefaults are the fragletc arguments found on a script's "#!" line, such as the
+// "#!/usr/bin/env -S fragletc --image=...@sha256:... --mode=main" lines pkg/save writes.
+type shebangDefaults struct {
+	VeinSpec string
+	Image    string
+	Mode     string
+	Env      []string
+	Secrets  []string
+	Params   []string
+}
+
+// applyShebang fills opts from the script file's fragletc shebang, so "fragletc file" runs it the
+// same way executing the file would. Flags already set win: a --vein or --image on the command
+// line replaces the shebang's target together with its mode, and command-line -p keys replace
+// the shebang's. Files without a fragletc shebang (or unreadable ones) leave opts unchanged.
+func applyShebang(opts RunOptions) RunOptions {
+	if opts.ScriptFile == "" || opts.InlineCode != "" {
+		return opts
+	}
+	d, ok := readShebang(opts.ScriptFile)
+	if !ok {
+		return opts
+	}
+	if opts.VeinSpec == "" && opts.Image == "" {
+		opts.VeinSpec, opts.Image = d.VeinSpec, d.Image
+		if opts.Mode == "" {
+			opts.Mode = d.Mode
+		} else if name, _, err := parseVeinSpec(opts.VeinSpec); err == nil {
+			opts.VeinSpec = name // --mode replaces a mode given as vein:mode in the shebang
+		}
+	}
+	// Executing the file passes the shebang's flags on the command line too; don't repeat them.
+	var env []string
+	for _, e := range d.Env {
+		if !slices.Contains(opts.EnvFlags, e) {
+			env = append(env, e)
+		}
+	}
+	opts.EnvFlags = append(env, opts.EnvFlags...)
+	var secrets []string
+	for _, s := range d.Secrets {
+		if !slices.Contains(opts.Secrets, s) {
+			secrets = append(secrets, s)
+		}
+	}
+	opts.Secrets = append(secrets, opts.Secrets...)
+	opts.ParamStrs = mergeParams(d.Params, opts.ParamStrs)
+	return opts
+}
+
+// readShebang parses the first line of path when it is a fragletc shebang.
+func readShebang(path string) (shebangDefaults, bool) {
+	line, ok := readFirstLine(path)
+	if !ok {
+		return shebangDefaults{}, false
+	}
+	return parseShebang(line)
+}
+
+// shebangInterpreter returns the interpreter path's "#!" line runs, such as "python3" for
+// "#!/usr/bin/env python3", so a vein of that name or alias can be inferred. It is "" when the
+// file has no shebang or runs fragletc itself.
+func shebangInterpreter(path string) string {
+	line, ok := readFirstLine(path)
+	if !ok {
+		return ""
+	}
+	name, _, ok := shebangCommand(line)
+	if !ok || name == "fragletc" {
+		return ""
+	}
+	return name
+}
+
+func readFirstLine(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+// shebangCommand splits a "#!" line into the base name of the program it runs, looking through
+// "/usr/bin/env [options]", and that program's arguments.
+func shebangCommand(line string) (name string, args []string, ok bool) {
+	rest, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return "", nil, false
+	}
+	fields := splitShebangArgs(rest)
+	i := 0
+	if i < len(fields) && filepath.Base(fields[i]) == "env" {
+		i++
+		for i < len(fields) && strings.HasPrefix(fields[i], "-") {
+			i++ // env's own options (-S, -i, ...)
+		}
+	}
+	if i >= len(fields) {
+		return "", nil, false
+	}
+	return strings.TrimSuffix(filepath.Base(fields[i]), ".exe"), fields[i+1:], true
+}
+
+// parseShebang extracts fragletc's arguments from a "#!" line: either fragletc itself as the
+// interpreter or "/usr/bin/env [-S] fragletc ...". Flags other than vein, image, mode, -e,
+// --secret and -p only take effect when the file is executed and are skipped here.
+func parseShebang(line string) (shebangDefaults, bool) {
+	var d shebangDefaults
+	name, args, ok := shebangCommand(line)
+	if !ok || name != "fragletc" {
+		return d, false
+	}
+	for j := 0; j < len(args); j++ {
+		if args[j] == "--" || !strings.HasPrefix(args[j], "-") {
+			break // script arguments follow
+		}
+		if arg := args[j]; len(arg) > 2 && strings.HasPrefix(arg, "-p") && arg[2] != '=' && strings.Contains(arg[2:], "=") {
+			d.Params = append(d.Params, arg[2:]) // -pKEY=value, as on the command line
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[j], "-"), "=")
+		if !hasValue {
+			switch name {
+			case "v", "vein", "i", "image", "m", "mode", "e", "secret", "p", "param",
+				"fraglet-path", "runner", "transport", "network", "timeout", "platform",
+				"memory", "cpus", "pids-limit", "ulimit", "max-output":
+				if j+1 < len(args) {
+					j++
+					value = args[j]
+				}
+			}
+		}
+		switch name {
+		case "v", "vein":
+			d.VeinSpec = value
+		case "i", "image":
+			d.Image = value
+		case "m", "mode":
+			d.Mode = value
+		case "e":
+			d.Env = append(d.Env, value)
+		case "secret":
+			d.Secrets = append(d.Secrets, value)
+		case "p", "param":
+			d.Params = append(d.Params, value)
+		}
+	}
+	return d, true
+}
+
+// splitShebangArgs splits like env -S: on whitespace, with single and double quotes grouping.
+func splitShebangArgs(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inField = r, true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inField = false
+			}
+		default:
+			cur.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// mergeParams returns the shebang's KEY=value params not overridden by a command-line param
+// with the same key, followed by the command-line params.
+func mergeParams(shebang, cli []string) []string {
+	if len(shebang) == 0 {
+		return cli
+	}
+	set := make(map[string]bool, len(cli))
+	for _, p := range cli {
+		key, _, _ := strings.Cut(p, "=")
+		set[key] = true
+	}
+	var out []string
+	for _, p := range shebang {
+		if key, _, _ := strings.Cut(p, "="); !set[key] {
+			out = append(out, p)
+		}
+	}
+	return append(out, cli...)
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseShebang(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want shebangDefaults
+		ok   bool
+	}{
+		{
+			name: "env -S with --flag=value",
+			line: "#!/usr/bin/env -S fragletc --image=100hellos/python@sha256:abc --mode=main",
+			want: shebangDefaults{Image: "100hellos/python@sha256:abc", Mode: "main"},
+			ok:   true,
+		},
+		{
+			name: "--flag value and short flags",
+			line: "#!/usr/local/bin/fragletc --vein python -m repl",
+			want: shebangDefaults{VeinSpec: "python", Mode: "repl"},
+			ok:   true,
+		},
+		{
+			name: "quoted values keep their spaces",
+			line: `#!/usr/bin/env -S fragletc -v python -p 'greeting=hello world' -p "who=the team"`,
+			want: shebangDefaults{VeinSpec: "python", Params: []string{"greeting=hello world", "who=the team"}},
+			ok:   true,
+		},
+		{
+			name: "-e, --secret and -p accumulate in every form",
+			line: "#!/usr/bin/env -S fragletc -v python -e A=1 -e=B --secret TOKEN --secret=KEY -p x=1 -p=y=2 -pz=3 --param w=4",
+			want: shebangDefaults{
+				VeinSpec: "python",
+				Env:      []string{"A=1", "B"},
+				Secrets:  []string{"TOKEN", "KEY"},
+				Params:   []string{"x=1", "y=2", "z=3", "w=4"},
+			},
+			ok: true,
+		},
+		{
+			name: "other flags and their values are skipped",
+			line: "#!/usr/bin/env -S fragletc --runner podman --memory 512m --network=none -v ruby",
+			want: shebangDefaults{VeinSpec: "ruby"},
+			ok:   true,
+		},
+		{
+			name: "--network value before --vein",
+			line: "#!/usr/bin/env -S fragletc --network none --vein=python",
+			want: shebangDefaults{VeinSpec: "python"},
+			ok:   true,
+		},
+		{
+			name: "--timeout value before --vein",
+			line: "#!/usr/bin/env -S fragletc --timeout 30s --vein=python",
+			want: shebangDefaults{VeinSpec: "python"},
+			ok:   true,
+		},
+		{
+			name: "--platform value before --vein",
+			line: "#!/usr/bin/env -S fragletc --platform linux/arm64 --vein=python",
+			want: shebangDefaults{VeinSpec: "python"},
+			ok:   true,
+		},
+		{
+			name: "script arguments end the flags",
+			line: "#!/usr/bin/env -S fragletc -v python -- -p ignored=1",
+			want: shebangDefaults{VeinSpec: "python"},
+			ok:   true,
+		},
+		{name: "another interpreter", line: "#!/usr/bin/env python3", ok: false},
+		{name: "another interpreter directly", line: "#!/bin/sh -e", ok: false},
+		{name: "not a shebang", line: "# fragletc -v python", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseShebang(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("parseShebang(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseShebang(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShebangInterpreter(t *testing.T) {
+	dir := t.TempDir()
+	for line, want := range map[string]string{
+		"#!/usr/bin/env node\n":                  "node",
+		"#!/usr/bin/python3\n":                   "python3",
+		"#!/usr/bin/env -S deno run --allow-all": "deno",
+		"#!/usr/bin/env -S fragletc --mode=main": "",
+		"print(1)\n":                             "",
+	} {
+		path := filepath.Join(dir, "script")
+		if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := shebangInterpreter(path); got != want {
+			t.Errorf("shebangInterpreter(%q) = %q, want %q", line, got, want)
+		}
+	}
+	if got := shebangInterpreter(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("missing file: %q", got)
+	}
+}
+
+func TestApplyShebang(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "report.py")
+	shebang := "#!/usr/bin/env -S fragletc -v python:main -e A=1 --secret TOKEN -p city=Paris -p units=metric\nprint(1)\n"
+	if err := os.WriteFile(script, []byte(shebang), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts RunOptions
+		want RunOptions
+	}{
+		{
+			name: "shebang fills unset options",
+			opts: RunOptions{ScriptFile: script},
+			want: RunOptions{ScriptFile: script, VeinSpec: "python:main",
+				EnvFlags: []string{"A=1"}, Secrets: []string{"TOKEN"}, ParamStrs: []string{"city=Paris", "units=metric"}},
+		},
+		{
+			name: "command-line target replaces the shebang's with its mode",
+			opts: RunOptions{ScriptFile: script, Image: "python:3.12"},
+			want: RunOptions{ScriptFile: script, Image: "python:3.12",
+				EnvFlags: []string{"A=1"}, Secrets: []string{"TOKEN"}, ParamStrs: []string{"city=Paris", "units=metric"}},
+		},
+		{
+			name: "--mode replaces a vein:mode",
+			opts: RunOptions{ScriptFile: script, Mode: "repl"},
+			want: RunOptions{ScriptFile: script, VeinSpec: "python", Mode: "repl",
+				EnvFlags: []string{"A=1"}, Secrets: []string{"TOKEN"}, ParamStrs: []string{"city=Paris", "units=metric"}},
+		},
+		{
+			name: "command-line params win by key, flags are not repeated",
+			opts: RunOptions{ScriptFile: script, EnvFlags: []string{"A=1", "B=2"}, Secrets: []string{"TOKEN"}, ParamStrs: []string{"city=Rome"}},
+			want: RunOptions{ScriptFile: script, VeinSpec: "python:main",
+				EnvFlags: []string{"A=1", "B=2"}, Secrets: []string{"TOKEN"}, ParamStrs: []string{"units=metric", "city=Rome"}},
+		},
+		{
+			name: "inline code ignores the file",
+			opts: RunOptions{ScriptFile: script, InlineCode: "print(2)"},
+			want: RunOptions{ScriptFile: script, InlineCode: "print(2)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyShebang(tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyShebang() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	plain := filepath.Join(t.TempDir(), "plain.py")
+	if err := os.WriteFile(plain, []byte("#!/usr/bin/env python3\nprint(1)\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	opts := RunOptions{ScriptFile: plain, ParamStrs: []string{"x=1"}}
+	if got := applyShebang(opts); !reflect.DeepEqual(got, opts) {
+		t.Errorf("non-fragletc shebang: applyShebang() = %+v", got)
+	}
+}
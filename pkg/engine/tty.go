@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ofthemachine/fraglet/pkg/runner"
+	"golang.org/x/term"
+)
+
+// startTTY puts the terminal behind in into raw mode, so keystrokes (including Ctrl-C) go to the
+// program's pseudo-terminal unprocessed, and reports the terminal's size now and on every resize.
+// restore undoes both and may be called more than once. When in is not a terminal nothing changes
+// and sizes is nil.
+func startTTY(in io.Reader, out io.Writer) (sizes <-chan runner.TermSize, restore func(), err error) {
+	inFile, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(inFile.Fd())) {
+		return nil, func() {}, nil
+	}
+	fd := int(inFile.Fd())
+	// The size is the output terminal's when there is one, as with docker run -t.
+	sizeFd := fd
+	if outFile, ok := out.(*os.File); ok && term.IsTerminal(int(outFile.Fd())) {
+		sizeFd = int(outFile.Fd())
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan runner.TermSize, 1)
+	send := func() {
+		cols, rows, err := term.GetSize(sizeFd)
+		if err != nil {
+			return
+		}
+		select {
+		case <-ch: // drop a stale size nobody applied yet
+		default:
+		}
+		ch <- runner.TermSize{Rows: uint16(rows), Cols: uint16(cols)}
+	}
+	send()
+	stopWatch := watchResize(send)
+
+	var once sync.Once
+	restore = func() {
+		once.Do(func() {
+			stopWatch()
+			_ = term.Restore(fd, state)
+		})
+	}
+	return ch, restore, nil
+}
+
+// IsTerminal reports whether f is a terminal; fragletc enables TTY mode when stdin and stdout are.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
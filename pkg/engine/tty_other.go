@@ -0,0 +1,8 @@
+//go:build !unix
+
+package engine
+
+// watchResize is a no-op without SIGWINCH; the terminal keeps its initial size.
+func watchResize(onResize func()) (stop func()) {
+	return func() {}
+}
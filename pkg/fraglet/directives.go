@@ -0,0 +1,38 @@
+package fraglet
+
+import "time"
+
+// Directives are the execution settings a fraglet declares for itself in fraglet-meta:
+//
+//	# fraglet-meta: vein=python mode=main network=none timeout=30s env=API_URL platform=linux/arm64
+//
+// They are defaults: command-line flags (and the fragletc shebang, which supplies flags) take
+// precedence, and directives in turn take precedence over vein defaults and file extensions.
+type Directives struct {
+	Vein     string        // vein name, optionally vein:mode
+	Mode     string        // FRAGLET_MODE
+	Network  string        // container network mode, e.g. "none"
+	Timeout  time.Duration // maximum run time; 0 = not declared
+	Env      []string      // NAME (forward the caller's value) or NAME=value, like fragletc -e
+	Platform string        // image platform, e.g. linux/arm64
+	Unknown  []string      // keys of key=value tokens fraglet-meta does not define, in order of appearance
+}
+
+// directiveKeys are the key=value tokens fraglet-meta understands besides the directives above.
+var directiveKeys = map[string]bool{
+	"param": true, "description": true, "d": true,
+	"vein": true, "mode": true, "network": true, "timeout": true, "env": true, "platform": true,
+}
+
+// Empty reports whether no directive is declared.
+func (d Directives) Empty() bool {
+	return d.Vein == "" && d.Mode == "" && d.Network == "" && d.Timeout == 0 && len(d.Env) == 0 && d.Platform == ""
+}
+
+// ParseDirectives extracts execution directives from fraglet-meta lines and blocks in code. A
+// directive declared twice keeps its last value; env= accumulates. Tokens without '=' are
+// annotations and description lines are free text, so neither is reported as unknown.
+func ParseDirectives(code string) (Directives, error) {
+	m, err := ParseMeta(code)
+	return m.Directives, err
+}
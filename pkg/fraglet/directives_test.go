@@ -0,0 +1,53 @@
+package fraglet
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseDirectives(t *testing.T) {
+	code := `#!/usr/bin/env -S fragletc
+# fraglet-meta: d=Fetches a page; needs=network is not a directive here.
+# fraglet-meta: vein=python mode=main determinism:deterministic param=city:required
+// fraglet-meta: network=none timeout=30s env=API_URL env=DEBUG=1 platform=linux/arm64
+# fraglet-meta: retries=3 cache=on retries=4
+print("hi")`
+	d, err := ParseDirectives(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Vein != "python" || d.Mode != "main" || d.Network != "none" || d.Platform != "linux/arm64" {
+		t.Errorf("directives = %+v", d)
+	}
+	if d.Timeout != 30*time.Second {
+		t.Errorf("timeout = %s, want 30s", d.Timeout)
+	}
+	if !slices.Equal(d.Env, []string{"API_URL", "DEBUG=1"}) {
+		t.Errorf("env = %v", d.Env)
+	}
+	if !slices.Equal(d.Unknown, []string{"retries", "cache"}) {
+		t.Errorf("unknown = %v, want [retries cache]", d.Unknown)
+	}
+	if d.Empty() {
+		t.Error("Empty() = true")
+	}
+}
+
+func TestParseDirectives_None(t *testing.T) {
+	d, err := ParseDirectives("# fraglet-meta: param=city\nprint(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() || d.Unknown != nil {
+		t.Errorf("directives = %+v, want none", d)
+	}
+}
+
+func TestParseDirectives_InvalidValues(t *testing.T) {
+	for _, code := range []string{"# fraglet-meta: timeout=soon", "# fraglet-meta: timeout=-1s", "# fraglet-meta: env="} {
+		if _, err := ParseDirectives(code); err == nil {
+			t.Errorf("%q: expected error", code)
+		}
+	}
+}
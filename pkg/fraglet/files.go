@@ -0,0 +1,114 @@
+package fraglet
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ParamFilesDir is the container directory file-valued params are mounted under.
+const ParamFilesDir = "/fraglet-params"
+
+// FileMount is a host file or directory a file-valued param mounts read-only in the container.
+type FileMount struct {
+	HostPath      string
+	ContainerPath string
+}
+
+// IsFile reports whether p refers to a host file or directory (-p name=@path).
+func (p Param) IsFile() bool {
+	return p.Encoding == "file"
+}
+
+// FileMounts replaces each file-valued param — an @path value, or any value of a type=file
+// declaration — with the container path its host file or directory is mounted at, and returns the
+// mounts. Host paths are made absolute and must exist; each param mounts at
+// ParamFilesDir/<env var>/<base name>, so the program sees the original file name. Only values the
+// caller gave are mounted: a declared default (Param.Default) passes through as the plain string
+// the fraglet declared, so fraglet code cannot mount host files on its own. Every missing path is
+// reported in one *InvalidParamsError.
+func (ps Params) FileMounts(decls []ParamDecl) (Params, []FileMount, error) {
+	byEnv := make(map[string]ParamDecl, len(decls))
+	for _, d := range decls {
+		byEnv[d.EnvVar] = d
+	}
+	out := make(Params, len(ps))
+	var mounts []FileMount
+	var problems []ParamProblem
+	for i, p := range ps {
+		out[i] = p
+		d, declared := byEnv[p.EnvVar]
+		if p.Default || !(p.IsFile() || declared && d.Type() == TypeFile) {
+			continue
+		}
+		name := strings.ToLower(p.EnvVar)
+		if declared {
+			name = d.Alias
+		}
+		hostPath := p.Value
+		if !p.IsFile() {
+			var err error
+			if hostPath, err = p.Decode(); err != nil {
+				problems = append(problems, ParamProblem{Name: name, Reason: err.Error()})
+				continue
+			}
+		}
+		abs, err := filepath.Abs(hostPath)
+		if err == nil {
+			_, err = os.Stat(abs)
+		}
+		if err != nil {
+			problems = append(problems, ParamProblem{Name: name, Reason: fmt.Sprintf("cannot read file %s: %v", hostPath, unwrapPathError(err))})
+			continue
+		}
+		containerPath := path.Join(ParamFilesDir, p.EnvVar, filepath.Base(abs))
+		mounts = append(mounts, FileMount{HostPath: abs, ContainerPath: containerPath})
+		out[i] = Param{EnvVar: p.EnvVar, Encoding: "raw", Value: containerPath}
+	}
+	if len(problems) > 0 {
+		return nil, nil, &InvalidParamsError{Problems: problems}
+	}
+	return out, mounts, nil
+}
+
+// WriteFiles materializes type=file params given inline, whose (decoded) value is the file content,
+// as files under dir and points the params at them, ready for FileMounts. It is for callers such as
+// the MCP server that receive content rather than host paths; the caller removes dir after the run.
+// Declared defaults are left as they are, as in FileMounts.
+func (ps Params) WriteFiles(decls []ParamDecl, dir string) (Params, error) {
+	byEnv := make(map[string]ParamDecl, len(decls))
+	for _, d := range decls {
+		byEnv[d.EnvVar] = d
+	}
+	out := make(Params, len(ps))
+	for i, p := range ps {
+		out[i] = p
+		d, ok := byEnv[p.EnvVar]
+		if !ok || d.Type() != TypeFile || p.IsFile() || p.Default {
+			continue
+		}
+		content, err := p.Decode()
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(dir, p.EnvVar, d.Alias)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+		out[i] = Param{EnvVar: p.EnvVar, Encoding: "file", Value: file}
+	}
+	return out, nil
+}
+
+// unwrapPathError drops the path from an *os.PathError, which the caller already reports.
+func unwrapPathError(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
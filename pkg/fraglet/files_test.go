@@ -0,0 +1,104 @@
+package fraglet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseParam_FileReference(t *testing.T) {
+	p, err := ParseParam("data=@./input.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsFile() || p.Value != "./input.csv" {
+		t.Errorf("param = %+v, want file ./input.csv", p)
+	}
+	if p, _ := ParseParam("handle=raw:@gopher"); p.IsFile() || p.Value != "@gopher" {
+		t.Errorf("raw:@ param = %+v, want literal @gopher", p)
+	}
+}
+
+func TestParams_FileMounts(t *testing.T) {
+	dir := t.TempDir()
+	csv := filepath.Join(dir, "input.csv")
+	if err := os.WriteFile(csv, []byte("a,b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	decls := ParseParamDecls("# fraglet-meta: param=config:type=file param=name")
+	ps := Params{
+		{EnvVar: "DATA", Encoding: "file", Value: csv},
+		{EnvVar: "CONFIG", Encoding: "raw", Value: dir}, // type=file takes a plain path, directories too
+		{EnvVar: "NAME", Encoding: "raw", Value: "x"},
+	}
+	got, mounts, err := ps.FileMounts(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData := "/fraglet-params/DATA/input.csv"
+	wantConfig := "/fraglet-params/CONFIG/" + filepath.Base(dir)
+	if got[0].Canonical() != "DATA=raw:"+wantData || got[1].Canonical() != "CONFIG=raw:"+wantConfig || got[2].Canonical() != "NAME=raw:x" {
+		t.Errorf("params = %v", got.ToCanonical())
+	}
+	if len(mounts) != 2 || mounts[0] != (FileMount{csv, wantData}) || mounts[1] != (FileMount{dir, wantConfig}) {
+		t.Errorf("mounts = %+v", mounts)
+	}
+
+	_, _, err = Params{{EnvVar: "CONFIG", Encoding: "file", Value: filepath.Join(dir, "missing")}}.FileMounts(decls)
+	var invalid *InvalidParamsError
+	if !errors.As(err, &invalid) || invalid.Problems[0].Name != "config" {
+		t.Errorf("err = %v, want invalid config", err)
+	}
+}
+
+func TestParams_WriteFiles(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=input:type=file param=name")
+	dir := t.TempDir()
+	ps := Params{{EnvVar: "INPUT", Encoding: "b64", Value: "aGVsbG8="}, {EnvVar: "NAME", Encoding: "raw", Value: "x"}}
+	got, err := ps.WriteFiles(decls, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got[0].IsFile() || got[1].IsFile() {
+		t.Fatalf("params = %+v", got)
+	}
+	data, err := os.ReadFile(got[0].Value)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("file content = %q, %v", data, err)
+	}
+}
+
+func TestParams_Validate_FileReference(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=n:type=int param=data:type=file")
+	if _, err := (Params{{EnvVar: "DATA", Encoding: "file", Value: "in.csv"}}).Validate(decls); err != nil {
+		t.Errorf("type=file: %v", err)
+	}
+	if _, err := (Params{{EnvVar: "N", Encoding: "file", Value: "in.csv"}}).Validate(decls); err == nil {
+		t.Error("type=int with @file: expected error")
+	}
+}
+
+func TestParams_FileMounts_DefaultNotMounted(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=config:type=file:default=/etc/passwd param=data:default=@/etc/passwd")
+	ps, err := Params{}.ApplyDecls(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps, err = ps.Validate(decls); err != nil {
+		t.Fatal(err)
+	}
+	if ps, err = ps.WriteFiles(decls, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	got, mounts, err := ps.FileMounts(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 0 {
+		t.Errorf("mounts = %+v, want none for defaults", mounts)
+	}
+	if got[0].Canonical() != "CONFIG=raw:/etc/passwd" || got[1].Canonical() != "DATA=raw:@/etc/passwd" {
+		t.Errorf("params = %v", got.ToCanonical())
+	}
+}
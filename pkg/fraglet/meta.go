@@ -1,9 +1,6 @@
 package fraglet
 
-import (
-	"sort"
-	"strings"
-)
+import "strings"
 
 const fragletMetaSentinel = "fraglet-meta:"
 
@@ -26,75 +23,34 @@ func (d ParamDecl) IsOptional() bool {
 	return !req
 }
 
+// IsSecret returns true if the param has a "secret" modifier: its value is kept off command lines,
+// masked in output and never saved.
+func (d ParamDecl) IsSecret() bool {
+	_, ok := d.Modifiers["secret"]
+	return ok
+}
+
 // Default returns the default value and whether one was declared.
 func (d ParamDecl) Default() (string, bool) {
 	v, ok := d.Modifiers["default"]
 	return v, ok
 }
 
-// ParseParamDecls extracts param= tokens from a code string.
-// Scans all lines for the "fraglet-meta:" sentinel, collects param= tokens.
-// Returns declarations sorted by alias for determinism.
+// ParseParamDecls extracts param declarations from a code string: param= tokens on
+// "fraglet-meta:" lines and the params of fraglet-meta blocks (see ParseMeta).
+// Returns declarations sorted by alias for determinism; a malformed block is ignored from
+// the point of the error (ParseDirectives and ParseMeta report it).
 func ParseParamDecls(code string) []ParamDecl {
-	var decls []ParamDecl
-	seen := make(map[string]bool)
-
-	for _, line := range strings.Split(code, "\n") {
-		idx := strings.Index(line, fragletMetaSentinel)
-		if idx < 0 {
-			continue
-		}
-		// Everything after the sentinel
-		rest := line[idx+len(fragletMetaSentinel):]
-		tokens := strings.Fields(rest)
-		for _, tok := range tokens {
-			if !strings.HasPrefix(tok, "param=") {
-				continue
-			}
-			decl := parseParamToken(tok[len("param="):])
-			if decl.Alias == "" {
-				continue
-			}
-			if seen[decl.Alias] {
-				continue // dedup
-			}
-			seen[decl.Alias] = true
-			decls = append(decls, decl)
-		}
-	}
-
-	sort.Slice(decls, func(i, j int) bool {
-		return decls[i].Alias < decls[j].Alias
-	})
-	return decls
+	m, _ := ParseMeta(code)
+	return m.Params
 }
 
 // ParseMetaDescription returns human-oriented text from fraglet-meta lines that are only
-// description=... or the short form d=... (one line per block; multiple lines are joined
-// with a blank line). Use a dedicated meta line per paragraph.
-// Multiline values inside a single description are a future format extension.
+// description=... or the short form d=... (multiple lines are joined with a blank line), and
+// from the description: of fraglet-meta blocks, which may span several lines.
 func ParseMetaDescription(code string) string {
-	var parts []string
-	for _, line := range strings.Split(code, "\n") {
-		idx := strings.Index(line, fragletMetaSentinel)
-		if idx < 0 {
-			continue
-		}
-		rest := strings.TrimSpace(line[idx+len(fragletMetaSentinel):])
-		var v string
-		switch {
-		case strings.HasPrefix(rest, "description="):
-			v = strings.TrimSpace(rest[len("description="):])
-		case strings.HasPrefix(rest, "d="):
-			v = strings.TrimSpace(rest[len("d="):])
-		default:
-			continue
-		}
-		if v != "" {
-			parts = append(parts, v)
-		}
-	}
-	return strings.Join(parts, "\n\n")
+	m, _ := ParseMeta(code)
+	return m.Description
 }
 
 // parseParamToken parses "alias[:modifier[:modifier...]]" into a ParamDecl.
@@ -113,7 +69,11 @@ func parseParamToken(s string) ParamDecl {
 			mods[part] = ""
 		}
 	}
+	return newParamDecl(alias, mods)
+}
 
+// newParamDecl builds a declaration from its alias and modifiers.
+func newParamDecl(alias string, mods map[string]string) ParamDecl {
 	// Resolve env var: explicit envvar= modifier, or alias uppercased
 	envVar := strings.ToUpper(alias)
 	if ev, ok := mods["envvar"]; ok {
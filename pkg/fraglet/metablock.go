@@ -0,0 +1,317 @@
+package fraglet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// metaFence opens and closes a fraglet-meta block: YAML between two "fraglet-meta: ---" lines,
+// inside whatever comment syntax the language uses.
+//
+//	# fraglet-meta: ---
+//	# description: |
+//	#   Looks up the weather.
+//	#   Prints one line per day.
+//	# params:
+//	#   city: {required: true, description: City name}
+//	#   units: {type: "enum(metric,imperial)", default: metric}
+//	# examples:
+//	#   - description: Three days in Paris
+//	#     params: {city: Paris, days: 3}
+//	# network: none
+//	# timeout: 30s
+//	# fraglet-meta: ---
+const metaFence = "---"
+
+// Meta is everything a fraglet declares about itself in fraglet-meta, from one-line
+// "key=value" tokens and fenced YAML blocks alike.
+type Meta struct {
+	Description string      // description=/d= lines and block descriptions, joined by a blank line
+	Params      []ParamDecl // sorted by alias; the first declaration of an alias wins
+	Examples    []Example   // block examples:
+	Annotations []string    // tokens without '=' (determinism:deterministic) and block annotations:
+	Directives  Directives
+}
+
+// Example is a documented way to run the fraglet.
+type Example struct {
+	Description string            `yaml:"description"`
+	Params      map[string]string `yaml:"params"`
+}
+
+// metaBlock is the YAML of a fenced block. Params stay a node so declarations keep their order
+// and each may be a modifier string ("required:type=int") or a mapping.
+type metaBlock struct {
+	Description string     `yaml:"description"`
+	Params      yaml.Node  `yaml:"params"`
+	Examples    []Example  `yaml:"examples"`
+	Annotations []string   `yaml:"annotations"`
+	Vein        string     `yaml:"vein"`
+	Mode        string     `yaml:"mode"`
+	Network     string     `yaml:"network"`
+	Timeout     string     `yaml:"timeout"`
+	Env         stringList `yaml:"env"`
+	Platform    string     `yaml:"platform"`
+}
+
+// blockKeys are the keys a block may use; others are reported in Directives.Unknown.
+var blockKeys = map[string]bool{
+	"description": true, "params": true, "examples": true, "annotations": true,
+	"vein": true, "mode": true, "network": true, "timeout": true, "env": true, "platform": true,
+}
+
+// stringList accepts a single string or a list of strings.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = stringList{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// metaParser accumulates Meta across the lines and blocks of one fraglet.
+type metaParser struct {
+	m            Meta
+	descriptions []string
+	seenParam    map[string]bool
+	seenUnknown  map[string]bool
+}
+
+// ParseMeta parses every fraglet-meta line and block in code. Directives declared twice keep
+// their last value and env accumulates, in order of appearance across both forms. On error the
+// Meta holds what was parsed before it.
+func ParseMeta(code string) (Meta, error) {
+	p := &metaParser{seenParam: map[string]bool{}, seenUnknown: map[string]bool{}}
+	err := p.parse(strings.Split(code, "\n"))
+	p.m.Description = strings.Join(p.descriptions, "\n\n")
+	sort.Slice(p.m.Params, func(i, j int) bool {
+		return p.m.Params[i].Alias < p.m.Params[j].Alias
+	})
+	return p.m, err
+}
+
+func (p *metaParser) parse(lines []string) error {
+	for i := 0; i < len(lines); i++ {
+		idx := strings.Index(lines[i], fragletMetaSentinel)
+		if idx < 0 {
+			continue
+		}
+		rest := strings.TrimSpace(lines[i][idx+len(fragletMetaSentinel):])
+		if !isMetaFence(rest) {
+			if err := p.line(rest); err != nil {
+				return err
+			}
+			continue
+		}
+		prefix := lines[i][:idx]
+		var body []string
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if k := strings.Index(lines[j], fragletMetaSentinel); k >= 0 && isMetaFence(strings.TrimSpace(lines[j][k+len(fragletMetaSentinel):])) {
+				end = j
+				break
+			}
+			body = append(body, stripCommentPrefix(lines[j], prefix))
+		}
+		if end < 0 {
+			return fmt.Errorf("fraglet-meta: block opened on line %d is not closed (end it with a fraglet-meta: %s line)", i+1, metaFence)
+		}
+		if err := p.block(strings.Join(body, "\n"), i+1); err != nil {
+			return err
+		}
+		i = end
+	}
+	return nil
+}
+
+// isMetaFence reports whether the text after the sentinel is a block fence; a comment closer
+// may follow ("--- */", "--- -->").
+func isMetaFence(rest string) bool {
+	return rest == metaFence || strings.HasPrefix(rest, metaFence+" ")
+}
+
+// stripCommentPrefix removes the comment syntax the opening fence used ("# ", "// ", "-- ")
+// from a line inside the block. Lines of a /* */ block may instead start with " * ".
+func stripCommentPrefix(line, prefix string) string {
+	if strings.HasPrefix(line, prefix) {
+		return line[len(prefix):]
+	}
+	if p := strings.TrimRight(prefix, " \t"); p != "" && strings.HasPrefix(line, p) {
+		return line[len(p):]
+	}
+	if strings.Contains(prefix, "/*") {
+		if rest, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "*"); ok {
+			return strings.TrimPrefix(rest, " ")
+		}
+	}
+	return line
+}
+
+// line handles a one-line "fraglet-meta: ..." declaration.
+func (p *metaParser) line(rest string) error {
+	for _, key := range []string{"description=", "d="} {
+		if v, ok := strings.CutPrefix(rest, key); ok {
+			if v = strings.TrimSpace(v); v != "" {
+				p.descriptions = append(p.descriptions, v)
+			}
+			return nil
+		}
+	}
+	for _, tok := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(tok, "=")
+		switch {
+		case !ok:
+			p.m.Annotations = append(p.m.Annotations, tok)
+		case key == "":
+		case key == "param":
+			p.param(parseParamToken(value))
+		default:
+			known, err := p.m.Directives.set(key, value)
+			if err != nil {
+				return err
+			}
+			if !known && !directiveKeys[key] {
+				p.unknown(key)
+			}
+		}
+	}
+	return nil
+}
+
+// block handles the YAML of a fenced block opened on line lineNo.
+func (p *metaParser) block(text string, lineNo int) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		return fmt.Errorf("fraglet-meta: block on line %d: %v", lineNo, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil // empty block
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("fraglet-meta: block on line %d must be a YAML mapping", lineNo)
+	}
+	var b metaBlock
+	if err := root.Decode(&b); err != nil {
+		return fmt.Errorf("fraglet-meta: block on line %d: %v", lineNo, err)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		switch {
+		case !blockKeys[key]:
+			p.unknown(key)
+		case key == "vein" || key == "mode" || key == "network" || key == "timeout" || key == "platform":
+			if _, err := p.m.Directives.set(key, value.Value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range b.Env {
+		if _, err := p.m.Directives.set("env", e); err != nil {
+			return err
+		}
+	}
+	if b.Description = strings.TrimSpace(b.Description); b.Description != "" {
+		p.descriptions = append(p.descriptions, b.Description)
+	}
+	p.m.Examples = append(p.m.Examples, b.Examples...)
+	p.m.Annotations = append(p.m.Annotations, b.Annotations...)
+	if b.Params.Kind == 0 {
+		return nil
+	}
+	if b.Params.Kind != yaml.MappingNode {
+		return fmt.Errorf("fraglet-meta: block on line %d: params must map names to modifiers", lineNo)
+	}
+	for i := 0; i+1 < len(b.Params.Content); i += 2 {
+		decl, err := blockParam(b.Params.Content[i].Value, b.Params.Content[i+1])
+		if err != nil {
+			return fmt.Errorf("fraglet-meta: block on line %d: %v", lineNo, err)
+		}
+		p.param(decl)
+	}
+	return nil
+}
+
+// blockParam builds a declaration from a block params entry: empty, a one-line modifier string
+// ("required:type=int"), or a mapping of modifiers where true marks a flag such as required.
+func blockParam(alias string, n *yaml.Node) (ParamDecl, error) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" || n.Value == "" {
+			return parseParamToken(alias), nil
+		}
+		return parseParamToken(alias + ":" + n.Value), nil
+	case yaml.MappingNode:
+		mods := make(map[string]string)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, v := n.Content[i].Value, n.Content[i+1]
+			if v.Kind != yaml.ScalarNode {
+				return ParamDecl{}, fmt.Errorf("param %s: %s must be a single value", alias, key)
+			}
+			switch {
+			case v.Tag == "!!bool" && v.Value == "true", v.Tag == "!!null":
+				mods[key] = ""
+			case v.Tag == "!!bool":
+				// false: the flag is not set
+			default:
+				mods[key] = v.Value
+			}
+		}
+		return newParamDecl(alias, mods), nil
+	}
+	return ParamDecl{}, fmt.Errorf("param %s: expected modifiers or a mapping", alias)
+}
+
+func (p *metaParser) param(d ParamDecl) {
+	if d.Alias == "" || p.seenParam[d.Alias] {
+		return // dedup: first declaration wins
+	}
+	p.seenParam[d.Alias] = true
+	p.m.Params = append(p.m.Params, d)
+}
+
+func (p *metaParser) unknown(key string) {
+	if !p.seenUnknown[key] {
+		p.seenUnknown[key] = true
+		p.m.Directives.Unknown = append(p.m.Directives.Unknown, key)
+	}
+}
+
+// set applies one directive; known is false for keys that are not directives.
+func (d *Directives) set(key, value string) (known bool, err error) {
+	switch key {
+	case "vein":
+		d.Vein = value
+	case "mode":
+		d.Mode = value
+	case "network":
+		d.Network = value
+	case "platform":
+		d.Platform = value
+	case "env":
+		if value == "" {
+			return true, fmt.Errorf("fraglet-meta: env= requires a variable name")
+		}
+		d.Env = append(d.Env, value)
+	case "timeout":
+		t, err := time.ParseDuration(value)
+		if err != nil || t <= 0 {
+			return true, fmt.Errorf("fraglet-meta: invalid timeout %q (use a duration such as 30s or 2m)", value)
+		}
+		d.Timeout = t
+	default:
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package fraglet
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMeta_Block(t *testing.T) {
+	code := `#!/usr/bin/env -S fragletc --vein=python
+# fraglet-meta: determinism:deterministic param=legacy:required
+# fraglet-meta: ---
+# description: |
+#   Looks up the weather.
+#
+#   Prints one line per day.
+# params:
+#   city: {required: true, description: City to look up}
+#   days: "type=int:min=1:default=3"
+#   units:
+#     type: enum(metric,imperial)
+#     default: metric
+#     secret: false
+#   host: {envvar: HURL_VARIABLE_host, pattern: "[a-z.]+:[0-9]+"}
+#   verbose:
+# examples:
+#   - description: Three days in Paris
+#     params: {city: Paris, days: 3}
+# annotations: [math:none]
+# network: none
+# timeout: 30s
+# env: API_URL
+# retries: 3
+# fraglet-meta: ---
+print("hi")`
+	m, err := ParseMeta(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Description != "Looks up the weather.\n\nPrints one line per day." {
+		t.Errorf("description = %q", m.Description)
+	}
+	var aliases []string
+	byAlias := map[string]ParamDecl{}
+	for _, d := range m.Params {
+		aliases = append(aliases, d.Alias)
+		byAlias[d.Alias] = d
+	}
+	if !slices.Equal(aliases, []string{"city", "days", "host", "legacy", "units", "verbose"}) {
+		t.Fatalf("params = %v", aliases)
+	}
+	if city := byAlias["city"]; !city.IsRequired() || city.Modifiers["description"] != "City to look up" {
+		t.Errorf("city = %+v", city)
+	}
+	if days := byAlias["days"]; days.Type() != TypeInt || days.Modifiers["min"] != "1" {
+		t.Errorf("days = %+v", days)
+	}
+	if units := byAlias["units"]; units.TypeLabel() != "enum(metric|imperial)" || units.IsSecret() {
+		t.Errorf("units = %+v", units)
+	}
+	if host := byAlias["host"]; host.EnvVar != "HURL_VARIABLE_host" || host.Modifiers["pattern"] != "[a-z.]+:[0-9]+" {
+		t.Errorf("host = %+v", host)
+	}
+	if len(m.Examples) != 1 || m.Examples[0].Params["days"] != "3" || m.Examples[0].Description != "Three days in Paris" {
+		t.Errorf("examples = %+v", m.Examples)
+	}
+	if !slices.Equal(m.Annotations, []string{"determinism:deterministic", "math:none"}) {
+		t.Errorf("annotations = %v", m.Annotations)
+	}
+	d := m.Directives
+	if d.Network != "none" || d.Timeout != 30*time.Second || !slices.Equal(d.Env, []string{"API_URL"}) {
+		t.Errorf("directives = %+v", d)
+	}
+	if !slices.Equal(d.Unknown, []string{"retries"}) {
+		t.Errorf("unknown = %v", d.Unknown)
+	}
+
+	// The existing entry points see the block too.
+	if got := ParseParamDecls(code); len(got) != 6 {
+		t.Errorf("ParseParamDecls = %d decls", len(got))
+	}
+	if !strings.HasPrefix(ParseMetaDescription(code), "Looks up the weather.") {
+		t.Errorf("ParseMetaDescription = %q", ParseMetaDescription(code))
+	}
+}
+
+func TestParseMeta_BlockCommentStyles(t *testing.T) {
+	for name, code := range map[string]string{
+		"slashes": "// fraglet-meta: ---\n// params:\n//   name: required\n// fraglet-meta: ---",
+		"dashes":  "-- fraglet-meta: ---\n-- params:\n--   name: required\n--\n-- fraglet-meta: ---",
+		"c block": "/* fraglet-meta: ---\n * params:\n *   name: required\n * fraglet-meta: --- */",
+		"bare":    "\"\"\"fraglet-meta: ---\nparams:\n  name: required\nfraglet-meta: ---\n\"\"\"",
+	} {
+		m, err := ParseMeta(code)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(m.Params) != 1 || m.Params[0].Alias != "name" || !m.Params[0].IsRequired() {
+			t.Errorf("%s: params = %+v", name, m.Params)
+		}
+	}
+}
+
+func TestParseMeta_BlockErrors(t *testing.T) {
+	for _, code := range []string{
+		"# fraglet-meta: ---\n# params:\n#   city: required",
+		"# fraglet-meta: ---\n# params: [city]\n# fraglet-meta: ---",
+		"# fraglet-meta: ---\n# timeout: soon\n# fraglet-meta: ---",
+		"# fraglet-meta: ---\n# - just a list\n# fraglet-meta: ---",
+		"# fraglet-meta: ---\n# params: {city: {required: [x]}}\n# fraglet-meta: ---",
+	} {
+		if _, err := ParseMeta(code); err == nil {
+			t.Errorf("%q: expected error", code)
+		}
+	}
+}
@@ -5,22 +5,35 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
 	"sort"
 	"strings"
 )
 
 const transportPrefix = "FRAGLET_PARAM_"
 
+// transportFilePrefix announces a value passed as a file: FRAGLET_PARAMFILE_<NAME>=<container path>.
+const transportFilePrefix = "FRAGLET_PARAMFILE_"
+
+// LargeParamBytes is the largest decoded value ToTransport passes as an env var. Linux limits each
+// env var and argv string to 128 KiB (MAX_ARG_STRLEN) and all of them together to ARG_MAX, and
+// the docker CLI puts every -e on its command line; larger values travel as mounted files.
+const LargeParamBytes = 64 << 10
+
 // reserved env var names that cannot be used as param targets.
 var reserved = map[string]bool{"CONFIG": true}
 
 // Param represents a typed parameter for fraglet execution.
 // EnvVar is the resolved env var name (e.g. "CITY", "HURL_VARIABLE_host").
-// Encoding is "raw", "b64", or "cb64". Value is the encoded form.
+// Encoding is "raw", "b64", "cb64", or "file" (Value is a host path). Value is the encoded form.
 type Param struct {
 	EnvVar   string // resolved env var name
-	Encoding string // "raw", "b64", "cb64"
+	Encoding string // "raw", "b64", "cb64", "file"
 	Value    string // encoded value as provided
+	Default  bool   // added by ApplyDecls from a declared default, not given by the caller
 }
 
 // ParseParam parses "KEY=value" or "KEY=type:value" into a Param.
@@ -46,8 +59,12 @@ func ParseParam(s string) (Param, error) {
 	return Param{EnvVar: envVar, Encoding: encoding, Value: value}, nil
 }
 
-// parseEncodedValue splits "type:value" or returns ("raw", value).
+// parseEncodedValue splits "type:value" or returns ("raw", value). "@path" refers to a host file
+// or directory (encoding "file"); use "raw:@..." for a literal leading '@'.
 func parseEncodedValue(s string) (encoding, value string) {
+	if path, ok := strings.CutPrefix(s, "@"); ok && path != "" {
+		return "file", path
+	}
 	// Check for known encoding prefixes
 	for _, enc := range []string{"raw:", "b64:", "cb64:"} {
 		if strings.HasPrefix(s, enc) {
@@ -62,6 +79,8 @@ func (p Param) Decode() (string, error) {
 	switch p.Encoding {
 	case "raw", "":
 		return p.Value, nil
+	case "file":
+		return "", fmt.Errorf("%q refers to host file %s; mount it with FileMounts first", p.EnvVar, p.Value)
 	case "b64":
 		data, err := base64.StdEncoding.DecodeString(p.Value)
 		if err != nil {
@@ -130,6 +149,43 @@ func (ps Params) ToTransportEnv() ([]string, error) {
 	return out, nil
 }
 
+// ToTransport is ToTransportEnv for values of any size. A decoded value over LargeParamBytes is
+// written to a temporary file, returned as a mount under ParamFilesDir/.values, and announced with
+// FRAGLET_PARAMFILE_<NAME>=<container path> instead of FRAGLET_PARAM_<NAME>; the entrypoint reads
+// it back. cleanup removes the temporary files once the run is over.
+func (ps Params) ToTransport() (env []string, mounts []FileMount, cleanup func(), err error) {
+	cleanup = func() {}
+	var dir string
+	for _, p := range ps {
+		decoded, err := p.Decode()
+		if err != nil {
+			cleanup()
+			return nil, nil, func() {}, err
+		}
+		if len(decoded) <= LargeParamBytes {
+			env = append(env, p.TransportEnvName()+"="+decoded)
+			continue
+		}
+		if dir == "" {
+			if dir, err = os.MkdirTemp("", "fraglet-values-*"); err != nil {
+				return nil, nil, cleanup, err
+			}
+			tmp := dir
+			cleanup = func() { os.RemoveAll(tmp) }
+		}
+		hostPath := filepath.Join(dir, p.EnvVar)
+		if err := os.WriteFile(hostPath, []byte(decoded), 0o644); err != nil {
+			cleanup()
+			return nil, nil, func() {}, err
+		}
+		containerPath := path.Join(ParamFilesDir, ".values", p.EnvVar)
+		mounts = append(mounts, FileMount{HostPath: hostPath, ContainerPath: containerPath})
+		env = append(env, transportFilePrefix+p.EnvVar+"="+containerPath)
+	}
+	sort.Strings(env)
+	return env, mounts, cleanup, nil
+}
+
 // ToCanonical returns sorted "key=type:value" pairs for hashing/ledger.
 func (ps Params) ToCanonical() []string {
 	if len(ps) == 0 {
@@ -175,3 +231,64 @@ func (ps Params) ResolveAliases(decls []ParamDecl) (Params, error) {
 	}
 	return resolved, nil
 }
+
+// SplitSecrets separates the params declared secret (matched by resolved env var), or whose env
+// var is in also (such as those supplied with --secret), from the rest. also may be nil.
+func (ps Params) SplitSecrets(decls []ParamDecl, also map[string]bool) (plain, secret Params) {
+	secretEnv := maps.Clone(also)
+	if secretEnv == nil {
+		secretEnv = make(map[string]bool)
+	}
+	for _, d := range decls {
+		if d.IsSecret() {
+			secretEnv[d.EnvVar] = true
+		}
+	}
+	for _, p := range ps {
+		if secretEnv[p.EnvVar] {
+			secret = append(secret, p)
+		} else {
+			plain = append(plain, p)
+		}
+	}
+	return plain, secret
+}
+
+// MissingParamsError reports required params that were not supplied, by alias.
+type MissingParamsError struct {
+	Missing []string
+}
+
+func (e *MissingParamsError) Error() string {
+	return "missing required params: " + strings.Join(e.Missing, ", ")
+}
+
+// ApplyDecls checks alias-resolved params against fraglet-meta declarations: every unset
+// required param is reported in one *MissingParamsError, and declared defaults are added for
+// unset optional params. Defaults are added as raw values, marked Default: an encoding prefix in a
+// default is not interpreted, so a fraglet cannot name a host file (@path) for itself. Called
+// host-side before any container starts.
+func (ps Params) ApplyDecls(decls []ParamDecl) (Params, error) {
+	set := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		set[p.EnvVar] = true
+	}
+	out := ps
+	var missing []string
+	for _, d := range decls {
+		if set[d.EnvVar] {
+			continue
+		}
+		if d.IsRequired() {
+			missing = append(missing, d.Alias)
+			continue
+		}
+		if def, ok := d.Default(); ok {
+			out = append(out, Param{EnvVar: d.EnvVar, Encoding: "raw", Value: def, Default: true})
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingParamsError{Missing: missing}
+	}
+	return out, nil
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"compress/zlib"
 	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -244,3 +247,112 @@ func TestParams_ResolveAliases_EmptyDecls(t *testing.T) {
 		t.Fatalf("EnvVar = %q, want CITY", resolved[0].EnvVar)
 	}
 }
+
+func TestParams_ApplyDecls_MissingRequired(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=city:required param=date:required param=units:default=metric")
+	_, err := Params{}.ApplyDecls(decls)
+	var missing *MissingParamsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want *MissingParamsError", err)
+	}
+	// Every missing name is reported at once.
+	if strings.Join(missing.Missing, ",") != "city,date" {
+		t.Fatalf("Missing = %v, want [city date]", missing.Missing)
+	}
+}
+
+func TestParams_ApplyDecls_Defaults(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=city:required param=units:default=metric param=port:envvar=HURL_VARIABLE_port:default=8080 param=note")
+	ps, err := Params{{EnvVar: "CITY", Encoding: "raw", Value: "paris"}, {EnvVar: "HURL_VARIABLE_port", Encoding: "raw", Value: "9000"}}.ApplyDecls(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := ps.ToTransportEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"FRAGLET_PARAM_CITY=paris", "FRAGLET_PARAM_HURL_VARIABLE_port=9000", "FRAGLET_PARAM_UNITS=metric"}
+	if strings.Join(env, " ") != strings.Join(want, " ") {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+}
+
+func TestParams_ApplyDecls_DefaultsRaw(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=data:default=@/etc/passwd")
+	ps, err := Params{}.ApplyDecls(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An @path default is kept literally; a default never becomes a file param.
+	if len(ps) != 1 || ps[0].IsFile() || !ps[0].Default || ps[0].Value != "@/etc/passwd" {
+		t.Errorf("params = %+v", ps)
+	}
+}
+
+func TestParams_Validate_InvalidDefault(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=n:type=int:default=ten")
+	ps, err := Params{}.ApplyDecls(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A bad default is the fraglet's error, not an invalid value from the caller.
+	_, err = ps.Validate(decls)
+	var invalid *InvalidParamsError
+	if err == nil || errors.As(err, &invalid) {
+		t.Errorf("err = %v, want a plain error", err)
+	}
+	if _, err := (Params{{EnvVar: "N", Encoding: "raw", Value: "3"}}).Validate(decls); err != nil {
+		t.Errorf("caller value: %v", err)
+	}
+}
+
+func TestParams_SplitSecrets(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=token:secret param=city")
+	if !decls[1].IsSecret() || decls[0].IsSecret() {
+		t.Fatalf("decls = %+v", decls)
+	}
+	plain, secret := Params{{EnvVar: "CITY", Value: "Paris"}, {EnvVar: "TOKEN", Value: "t0ken"}}.SplitSecrets(decls, nil)
+	if len(plain) != 1 || plain[0].EnvVar != "CITY" || len(secret) != 1 || secret[0].EnvVar != "TOKEN" {
+		t.Errorf("plain = %v, secret = %v", plain, secret)
+	}
+	// Env vars made secret by the caller (--secret) split off too.
+	plain, secret = Params{{EnvVar: "CITY", Value: "Paris"}}.SplitSecrets(decls, map[string]bool{"CITY": true})
+	if len(plain) != 0 || len(secret) != 1 {
+		t.Errorf("also: plain = %v, secret = %v", plain, secret)
+	}
+	if schema := ParamsSchema(decls); schema["properties"].(map[string]any)["token"].(map[string]any)["writeOnly"] != true {
+		t.Errorf("schema = %v", schema)
+	}
+}
+
+func TestParams_ToTransport_LargeValue(t *testing.T) {
+	large := strings.Repeat("x", LargeParamBytes+1)
+	ps := Params{
+		{EnvVar: "SMALL", Encoding: "raw", Value: strings.Repeat("s", LargeParamBytes)},
+		{EnvVar: "BIG", Encoding: "raw", Value: large},
+	}
+	env, mounts, cleanup, err := ps.ToTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 2 || env[0] != "FRAGLET_PARAMFILE_BIG=/fraglet-params/.values/BIG" || !strings.HasPrefix(env[1], "FRAGLET_PARAM_SMALL=sss") {
+		t.Fatalf("env = %.80q", env)
+	}
+	if len(mounts) != 1 || mounts[0].ContainerPath != "/fraglet-params/.values/BIG" {
+		t.Fatalf("mounts = %+v", mounts)
+	}
+	if data, err := os.ReadFile(mounts[0].HostPath); err != nil || string(data) != large {
+		t.Errorf("value file: %d bytes, %v", len(data), err)
+	}
+	cleanup()
+	if _, err := os.Stat(mounts[0].HostPath); !os.IsNotExist(err) {
+		t.Errorf("value file not removed: %v", err)
+	}
+
+	// Small values only: no files, nothing to clean up.
+	env, mounts, cleanup, err = Params{{EnvVar: "A", Value: "1"}}.ToTransport()
+	cleanup()
+	if err != nil || len(mounts) != 0 || len(env) != 1 || env[0] != "FRAGLET_PARAM_A=1" {
+		t.Errorf("env = %v, mounts = %v, err = %v", env, mounts, err)
+	}
+}
@@ -0,0 +1,299 @@
+package fraglet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Param types a declaration may give with the type= modifier; enum(a,b,c) lists its values.
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeJSON   = "json"
+	TypeEnum   = "enum"
+	TypeFile   = "file" // a host file or directory, mounted read-only; the value becomes its container path
+)
+
+// Type returns the declared type: TypeString unless a type= modifier says otherwise.
+func (d ParamDecl) Type() string {
+	t, ok := d.Modifiers["type"]
+	if !ok || t == "" {
+		return TypeString
+	}
+	if strings.HasPrefix(t, "enum(") {
+		return TypeEnum
+	}
+	return t
+}
+
+// EnumValues returns the values of a type=enum(a,b,c) declaration, nil for other types.
+func (d ParamDecl) EnumValues() []string {
+	t := d.Modifiers["type"]
+	if !strings.HasPrefix(t, "enum(") || !strings.HasSuffix(t, ")") {
+		return nil
+	}
+	inner := t[len("enum(") : len(t)-1]
+	if inner == "" {
+		return nil
+	}
+	return strings.Split(inner, ",")
+}
+
+// TypeLabel describes the type for help output: "int", "enum(a|b|c)", "string".
+func (d ParamDecl) TypeLabel() string {
+	if d.Type() == TypeEnum {
+		return "enum(" + strings.Join(d.EnumValues(), "|") + ")"
+	}
+	return d.Type()
+}
+
+// Normalize validates value against the declaration's type, min=, max= and pattern= modifiers and
+// returns its canonical form: integers and floats as Go formats them, bools as "true"/"false", JSON
+// compacted. min/max bound the value of int and float params and the length of string params;
+// pattern= is a regular expression the whole value must match (it cannot contain ':' or spaces,
+// which separate modifiers and tokens).
+func (d ParamDecl) Normalize(value string) (string, error) {
+	var out string
+	switch d.Type() {
+	case TypeString:
+		out = value
+		n := float64(utf8.RuneCountInString(value))
+		if err := d.checkBounds(n, "length"); err != nil {
+			return "", err
+		}
+	case TypeInt:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an integer", value)
+		}
+		if err := d.checkBounds(float64(n), "value"); err != nil {
+			return "", err
+		}
+		out = strconv.FormatInt(n, 10)
+	case TypeFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		if err := d.checkBounds(f, "value"); err != nil {
+			return "", err
+		}
+		out = strconv.FormatFloat(f, 'g', -1, 64)
+	case TypeBool:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "yes", "on", "1":
+			out = "true"
+		case "false", "no", "off", "0":
+			out = "false"
+		default:
+			return "", fmt.Errorf("%q is not a boolean (true/false)", value)
+		}
+	case TypeJSON:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(value)); err != nil {
+			return "", fmt.Errorf("invalid JSON: %v", err)
+		}
+		out = buf.String()
+	case TypeFile:
+		out = value // a host path; FileMounts checks that it exists (a default is never mounted)
+	case TypeEnum:
+		values := d.EnumValues()
+		if !slices.Contains(values, value) {
+			return "", fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", "))
+		}
+		out = value
+	default:
+		return "", fmt.Errorf("unknown type %q declared", d.Type())
+	}
+	if pattern, ok := d.Modifiers["pattern"]; ok {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return "", fmt.Errorf("invalid pattern %q declared: %v", pattern, err)
+		}
+		if !re.MatchString(out) {
+			return "", fmt.Errorf("%q does not match pattern %s", value, pattern)
+		}
+	}
+	return out, nil
+}
+
+// checkBounds applies min= and max= to n, which is the value or the length (what).
+func (d ParamDecl) checkBounds(n float64, what string) error {
+	for _, bound := range []string{"min", "max"} {
+		s, ok := d.Modifiers[bound]
+		if !ok {
+			continue
+		}
+		limit, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s=%q declared", bound, s)
+		}
+		if bound == "min" && n < limit {
+			return fmt.Errorf("%s %s is below the minimum %s", what, strconv.FormatFloat(n, 'g', -1, 64), s)
+		}
+		if bound == "max" && n > limit {
+			return fmt.Errorf("%s %s is above the maximum %s", what, strconv.FormatFloat(n, 'g', -1, 64), s)
+		}
+	}
+	return nil
+}
+
+// ParamProblem is one param value that failed its declaration.
+type ParamProblem struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// InvalidParamsError reports every param value that failed validation.
+type InvalidParamsError struct {
+	Problems []ParamProblem
+}
+
+func (e *InvalidParamsError) Error() string {
+	parts := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		parts[i] = p.Name + ": " + p.Reason
+	}
+	return "invalid params: " + strings.Join(parts, "; ")
+}
+
+// Validate checks each param against its declaration (matched by resolved env var) and replaces
+// its value with the normalized, raw-encoded form. Undeclared params pass through unchanged.
+// Every failure of a caller's value is reported in one *InvalidParamsError; a declared default
+// that fails is the fraglet's error, not the caller's, and is reported as a plain error.
+func (ps Params) Validate(decls []ParamDecl) (Params, error) {
+	byEnv := make(map[string]ParamDecl, len(decls))
+	for _, d := range decls {
+		byEnv[d.EnvVar] = d
+	}
+	out := make(Params, len(ps))
+	var problems []ParamProblem
+	for i, p := range ps {
+		out[i] = p
+		d, ok := byEnv[p.EnvVar]
+		if !ok {
+			continue
+		}
+		if p.IsFile() {
+			if t := d.Type(); t != TypeFile && t != TypeString {
+				problems = append(problems, ParamProblem{Name: d.Alias, Reason: fmt.Sprintf("a file (@%s) cannot be passed as %s", p.Value, d.TypeLabel())})
+			}
+			continue // FileMounts resolves it
+		}
+		value, err := p.Decode()
+		if err == nil {
+			value, err = d.Normalize(value)
+		}
+		if err != nil && p.Default {
+			return nil, fmt.Errorf("param %s: invalid default declared: %v", d.Alias, err)
+		}
+		if err != nil {
+			problems = append(problems, ParamProblem{Name: d.Alias, Reason: err.Error()})
+			continue
+		}
+		out[i] = Param{EnvVar: p.EnvVar, Encoding: "raw", Value: value, Default: p.Default}
+	}
+	if len(problems) > 0 {
+		return nil, &InvalidParamsError{Problems: problems}
+	}
+	return out, nil
+}
+
+// ParamsSchema describes the declarations as a JSON Schema object whose properties are keyed by
+// alias. Values are passed as strings (-p name=value, MCP params); the schema gives the type they
+// must parse as, so int and float map to integer and number, bool to boolean, json to any value.
+func ParamsSchema(decls []ParamDecl) map[string]any {
+	props := make(map[string]any, len(decls))
+	var required []string
+	for _, d := range decls {
+		p := map[string]any{}
+		switch d.Type() {
+		case TypeInt:
+			p["type"] = "integer"
+		case TypeFloat:
+			p["type"] = "number"
+		case TypeBool:
+			p["type"] = "boolean"
+		case TypeJSON:
+		case TypeEnum:
+			p["type"] = "string"
+			p["enum"] = d.EnumValues()
+		case TypeFile:
+			p["type"] = "string"
+			p["x-fraglet-file"] = true // a host path (-p name=@path) or, over MCP, the content
+		default:
+			p["type"] = "string"
+		}
+		for bound, key := range map[string][2]string{"min": {"minimum", "minLength"}, "max": {"maximum", "maxLength"}} {
+			s, ok := d.Modifiers[bound]
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				continue
+			}
+			switch d.Type() {
+			case TypeInt, TypeFloat:
+				p[key[0]] = n
+			case TypeString:
+				p[key[1]] = int(n)
+			}
+		}
+		if pattern, ok := d.Modifiers["pattern"]; ok && d.Type() == TypeString {
+			p["pattern"] = "^(?:" + pattern + ")$"
+		}
+		if def, ok := d.Default(); ok {
+			if v, err := d.Normalize(def); err == nil {
+				p["default"] = schemaValue(d, v)
+			}
+		}
+		if d.EnvVar != strings.ToUpper(d.Alias) {
+			p["x-envvar"] = d.EnvVar
+		}
+		if d.IsSecret() {
+			p["writeOnly"] = true
+		}
+		if about := d.Modifiers["description"]; about != "" {
+			p["description"] = about
+		}
+		props[d.Alias] = p
+		if d.IsRequired() {
+			required = append(required, d.Alias)
+		}
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// schemaValue converts a normalized value to its JSON form for the schema.
+func schemaValue(d ParamDecl, v string) any {
+	switch d.Type() {
+	case TypeInt:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	case TypeFloat:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	case TypeBool:
+		return v == "true"
+	case TypeJSON:
+		return json.RawMessage(v)
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+package fraglet
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParamDecl_Normalize(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=n:type=int:min=1:max=10 param=ratio:type=float param=verbose:type=bool " +
+		"param=cfg:type=json param=units:type=enum(metric,imperial) param=code:pattern=[A-Z]{3}:max=3 param=name")
+	byAlias := map[string]ParamDecl{}
+	for _, d := range decls {
+		byAlias[d.Alias] = d
+	}
+	tests := []struct {
+		alias, in, want string
+		wantErr         bool
+	}{
+		{"n", " 07", "7", false},
+		{"n", "11", "", true},
+		{"n", "0", "", true},
+		{"n", "1.5", "", true},
+		{"ratio", "2.50", "2.5", false},
+		{"ratio", "x", "", true},
+		{"verbose", "YES", "true", false},
+		{"verbose", "0", "false", false},
+		{"verbose", "maybe", "", true},
+		{"cfg", `{ "a": [1, 2] }`, `{"a":[1,2]}`, false},
+		{"cfg", `{a}`, "", true},
+		{"units", "imperial", "imperial", false},
+		{"units", "kelvin", "", true},
+		{"code", "ABC", "ABC", false},
+		{"code", "ABCD", "", true},
+		{"code", "abc", "", true},
+		{"name", "anything at all", "anything at all", false},
+	}
+	for _, tt := range tests {
+		got, err := byAlias[tt.alias].Normalize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, %v; want %q, err=%v", tt.alias, tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if got := byAlias["units"].TypeLabel(); got != "enum(metric|imperial)" {
+		t.Errorf("TypeLabel = %q", got)
+	}
+}
+
+func TestParams_Validate(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=n:type=int param=flag:type=bool param=units:type=enum(metric,imperial)")
+	ps := Params{
+		{EnvVar: "N", Encoding: "b64", Value: "NDI="}, // "42"
+		{EnvVar: "FLAG", Encoding: "raw", Value: "on"},
+		{EnvVar: "OTHER", Encoding: "raw", Value: "x"},
+	}
+	got, err := ps.Validate(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Canonical() != "N=raw:42" || got[1].Canonical() != "FLAG=raw:true" || got[2].Canonical() != "OTHER=raw:x" {
+		t.Errorf("validated = %v", got.ToCanonical())
+	}
+
+	// Every invalid value is reported at once.
+	_, err = Params{{EnvVar: "N", Value: "many"}, {EnvVar: "UNITS", Value: "kelvin"}}.Validate(decls)
+	var invalid *InvalidParamsError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 2 || invalid.Problems[0].Name != "n" || invalid.Problems[1].Name != "units" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParamsSchema(t *testing.T) {
+	decls := ParseParamDecls("# fraglet-meta: param=n:type=int:min=1:required param=units:type=enum(metric,imperial):default=metric " +
+		"param=host:envvar=HURL_VARIABLE_host:pattern=[a-z.]+ param=cfg:type=json")
+	data, err := json.Marshal(ParamsSchema(decls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"additionalProperties":false,"properties":{` +
+		`"cfg":{},` +
+		`"host":{"pattern":"^(?:[a-z.]+)$","type":"string","x-envvar":"HURL_VARIABLE_host"},` +
+		`"n":{"minimum":1,"type":"integer"},` +
+		`"units":{"default":"metric","enum":["metric","imperial"],"type":"string"}},` +
+		`"required":["n"],"type":"object"}`
+	if string(data) != want {
+		t.Errorf("schema =\n%s\nwant\n%s", data, want)
+	}
+}
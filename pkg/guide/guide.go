@@ -10,7 +10,7 @@ import (
 
 // Run executes the "guide" command in the container for the given vein and optional mode,
 // or uses container image directly when image is non-empty (veinName must then be empty).
-// Uses the same vein loading and runner path as the CLI; respects FRAGLET_VEINS_PATH for vein lookup.
+// Uses the same vein loading and runner path as the CLI, including the user, project and FRAGLET_VEINS_PATH vein layers.
 // When mode is non-empty, sets FRAGLET_MODE for the container entrypoint.
 func Run(ctx context.Context, registry *vein.VeinRegistry, veinName, mode, image string) (runner.RunResult, error) {
 	if image != "" && veinName != "" {
@@ -27,9 +27,12 @@ func Run(ctx context.Context, registry *vein.VeinRegistry, veinName, mode, image
 		if registry == nil {
 			return runner.RunResult{}, fmt.Errorf("vein registry required for vein lookup")
 		}
-		v, ok := registry.Get(veinName)
-		if !ok {
-			return runner.RunResult{}, fmt.Errorf("vein not found: %s", veinName)
+		v, err := registry.Resolve(veinName)
+		if err != nil {
+			return runner.RunResult{}, err
+		}
+		if err := v.CheckMode(mode); err != nil {
+			return runner.RunResult{}, err
 		}
 		img = v.ContainerImage()
 	}
@@ -37,7 +40,10 @@ func Run(ctx context.Context, registry *vein.VeinRegistry, veinName, mode, image
 	if mode != "" {
 		envVars = append(envVars, fmt.Sprintf("FRAGLET_MODE=%s", mode))
 	}
-	r := runner.NewRunner(img, "")
+	r, err := runner.NewRunner(img)
+	if err != nil {
+		return runner.RunResult{}, err
+	}
 	spec := runner.RunSpec{
 		Container: img,
 		Env:       envVars,
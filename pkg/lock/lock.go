@@ -0,0 +1,104 @@
+// Package lock reads and writes fraglet.lock, which pins vein images to registry digests so
+// runs keep using the same image after the tag moves.
+//
+//	# Generated by fragletc lock; do not edit.
+//	veins:
+//	  python:
+//	    container: 100hellos/python:latest
+//	    digest: 100hellos/python@sha256:4f1c...
+//
+// fragletc finds the lockfile in the working directory or its nearest parent that has one.
+package lock
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ofthemachine/fraglet/pkg/vein"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the lockfile's name.
+const FileName = "fraglet.lock"
+
+const header = "# Generated by fragletc lock; do not edit.\n"
+
+// Entry pins one vein.
+type Entry struct {
+	Container string `yaml:"container"` // the vein's container when it was locked
+	Digest    string `yaml:"digest"`    // repo@sha256:... to run instead
+}
+
+// Lock is the contents of fraglet.lock.
+type Lock struct {
+	Veins map[string]Entry `yaml:"veins"`
+}
+
+// Find returns the nearest fraglet.lock from dir upwards, or "" when there is none.
+func Find(dir string) string {
+	for {
+		p := filepath.Join(dir, FileName)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads a lockfile. A missing file yields an empty Lock.
+func Load(path string) (*Lock, error) {
+	l := &Lock{Veins: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if l.Veins == nil {
+		l.Veins = make(map[string]Entry)
+	}
+	for name, e := range l.Veins {
+		if e.Container == "" || e.Digest == "" {
+			return nil, fmt.Errorf("%s: %s: container and digest are required", path, name)
+		}
+	}
+	return l, nil
+}
+
+// Save writes the lockfile, veins sorted by name.
+func (l *Lock) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Pin returns the digest the lock pins v to. ok is false when v is not locked; stale is set when
+// it was locked for a container other than the one the vein now uses, and the pin is not applied.
+func (l *Lock) Pin(v *vein.Vein) (digest string, ok bool, stale *Entry) {
+	e, found := l.Veins[v.Name]
+	if !found {
+		return "", false, nil
+	}
+	if e.Container != v.Container {
+		return "", false, &e
+	}
+	return e.Digest, true, nil
+}
@@ -0,0 +1,94 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/vein"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	l, err := Load(path)
+	if err != nil || len(l.Veins) != 0 {
+		t.Fatalf("missing file: %+v, %v", l, err)
+	}
+
+	l.Veins["ruby"] = Entry{Container: "100hellos/ruby:latest", Digest: "100hellos/ruby@sha256:bbbb"}
+	l.Veins["python"] = Entry{Container: "100hellos/python:latest", Digest: "100hellos/python@sha256:aaaa"}
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := header + `veins:
+  python:
+    container: 100hellos/python:latest
+    digest: 100hellos/python@sha256:aaaa
+  ruby:
+    container: 100hellos/ruby:latest
+    digest: 100hellos/ruby@sha256:bbbb
+`
+	if string(data) != want {
+		t.Errorf("saved:\n%s\nwant:\n%s", data, want)
+	}
+
+	got, err := Load(path)
+	if err != nil || !reflect.DeepEqual(got, l) {
+		t.Errorf("Load() = %+v, %v; want %+v", got, err, l)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("veins:\n  python:\n    container: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "python: container and digest are required") {
+		t.Errorf("Load() = %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := Find(sub); got != "" {
+		t.Errorf("Find() without a lockfile = %q", got)
+	}
+	path := filepath.Join(root, "a", FileName)
+	if err := os.WriteFile(path, []byte("veins: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Find(sub); got != path {
+		t.Errorf("Find() = %q, want %q", got, path)
+	}
+}
+
+func TestPin(t *testing.T) {
+	l := &Lock{Veins: map[string]Entry{
+		"python": {Container: "100hellos/python:latest", Digest: "100hellos/python@sha256:aaaa"},
+	}}
+
+	digest, ok, stale := l.Pin(&vein.Vein{Name: "python", Container: "100hellos/python:latest"})
+	if !ok || stale != nil || digest != "100hellos/python@sha256:aaaa" {
+		t.Errorf("Pin(python) = %q, %v, %v", digest, ok, stale)
+	}
+
+	_, ok, stale = l.Pin(&vein.Vein{Name: "python", Container: "mirror/python:latest"})
+	if ok || stale == nil || stale.Container != "100hellos/python:latest" {
+		t.Errorf("Pin(changed container) = %v, %v", ok, stale)
+	}
+
+	if _, ok, stale = l.Pin(&vein.Vein{Name: "ruby", Container: "100hellos/ruby:latest"}); ok || stale != nil {
+		t.Errorf("Pin(unlocked) = %v, %v", ok, stale)
+	}
+}
@@ -0,0 +1,104 @@
+// Package redact masks secret values in program output and in anything reported or saved about a run.
+package redact
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Mask replaces each occurrence of a secret.
+const Mask = "********"
+
+// MinLength is the shortest value masked; shorter values would mask ordinary output.
+const MinLength = 4
+
+// Redactor masks a fixed set of secret values. A nil *Redactor masks nothing.
+type Redactor struct {
+	secrets []string // longest first, so a secret containing another is masked whole
+}
+
+// New returns a Redactor for the given values; empty and shorter-than-MinLength values are ignored.
+// It returns nil when nothing is left to mask.
+func New(secrets ...string) *Redactor {
+	var kept []string
+	for _, s := range secrets {
+		if len(s) >= MinLength {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return len(kept[i]) > len(kept[j]) })
+	return &Redactor{secrets: kept}
+}
+
+// String returns s with every secret replaced by Mask.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// Writer returns a writer that masks secrets in what is written to w, including secrets split
+// across writes: a trailing partial match is held back until the next Write or Flush. A nil
+// Redactor returns a writer that passes everything through.
+func (r *Redactor) Writer(w io.Writer) *Writer {
+	return &Writer{r: r, w: w}
+}
+
+// Writer masks secrets in a stream; see Redactor.Writer. Call Flush once the stream ends.
+type Writer struct {
+	r       *Redactor
+	w       io.Writer
+	pending []byte
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.r == nil {
+		return w.w.Write(p)
+	}
+	buf := append(w.pending, p...)
+	var out []byte
+	i := 0
+scan:
+	for i < len(buf) {
+		rest := buf[i:]
+		for _, secret := range w.r.secrets {
+			if len(rest) >= len(secret) && string(rest[:len(secret)]) == secret {
+				out = append(out, Mask...)
+				i += len(secret)
+				continue scan
+			}
+		}
+		for _, secret := range w.r.secrets {
+			if len(rest) < len(secret) && strings.HasPrefix(secret, string(rest)) {
+				break scan // may complete in the next write
+			}
+		}
+		out = append(out, buf[i])
+		i++
+	}
+	w.pending = append([]byte(nil), buf[i:]...)
+	if len(out) > 0 {
+		if _, err := w.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any held-back partial match, which did not turn out to be a secret.
+func (w *Writer) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.pending)
+	w.pending = nil
+	return err
+}
@@ -0,0 +1,37 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r := New("s3cr3t-token", "s3cr3t", "", "ab")
+	got := r.String("token=s3cr3t-token other=s3cr3t short=ab")
+	if want := "token=" + Mask + " other=" + Mask + " short=ab"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	var none *Redactor
+	if none.String("s3cr3t") != "s3cr3t" || New("ab") != nil {
+		t.Error("nil Redactor should mask nothing")
+	}
+}
+
+func TestWriter_SplitAcrossWrites(t *testing.T) {
+	var out strings.Builder
+	w := New("hunter2").Writer(&out)
+	for _, chunk := range []string{"pass: hun", "ter", "2\nhun", "gry\nhu"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if got := out.String(); got != "pass: "+Mask+"\nhungry\n" {
+		t.Errorf("before Flush = %q", got)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "pass: "+Mask+"\nhungry\nhu" {
+		t.Errorf("after Flush = %q", got)
+	}
+}
@@ -0,0 +1,316 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+)
+
+// removeTimeout bounds container removal after a run; it runs on a fresh context so a
+// cancelled run still cleans up. A variable so tests can shorten it.
+var removeTimeout = 30 * time.Second
+
+// chanWriter adapts a string channel to io.Writer for streaming results.
+type chanWriter chan<- string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+// apiContainerConfig maps a RunSpec onto a create request, mirroring the docker CLI argv cases.
+// With copyFiles the read-only file volumes (and any temp script) are returned in copies for
+// upload after create instead of being bind-mounted.
+// The returned cleanup removes any temp script and must be called once the container is gone.
+func apiContainerConfig(spec RunSpec, copyFiles bool) (cfg dockerapi.ContainerConfig, copies []VolumeMount, cleanup func(), err error) {
+	attachStdin := spec.StdinReader != nil || spec.Stdin != ""
+	cfg = dockerapi.ContainerConfig{
+		Image:        spec.Container,
+		Env:          spec.env(),
+		WorkingDir:   spec.WorkDir,
+		AttachStdin:  attachStdin,
+		AttachStdout: true,
+		AttachStderr: true,
+		OpenStdin:    attachStdin,
+		StdinOnce:    attachStdin,
+		Tty:          spec.TTY,
+		HostConfig: dockerapi.HostConfig{
+			NetworkMode: spec.NetworkMode,
+			CapDrop:     []string{"ALL"},
+			SecurityOpt: []string{"no-new-privileges"},
+		},
+	}
+	applyAPILimits(&cfg.HostConfig, spec.Limits)
+
+	volumes := spec.Volumes
+	cleanup = func() {}
+	switch {
+	case spec.Entrypoint != "" && spec.Command != "":
+		// Entrypoint + command: write command to temp file, mount it, run via entrypoint.
+		tempFile, cleanupFn, err := writeTempScript(spec.Command)
+		if err != nil {
+			return cfg, nil, nil, fmt.Errorf("failed to create temp script: %w", err)
+		}
+		cleanup = cleanupFn
+		cfg.Entrypoint = []string{spec.Entrypoint}
+		volumes = append([]VolumeMount{{HostPath: tempFile, ContainerPath: "/tmp/script"}}, volumes...)
+		cfg.Cmd = append([]string{"/tmp/script"}, spec.Args...)
+	case spec.Entrypoint != "":
+		cfg.Entrypoint = []string{spec.Entrypoint}
+		cfg.Cmd = spec.Args
+	case spec.Command != "":
+		// Command via sh -c; args don't apply.
+		cfg.Cmd = []string{"sh", "-c", spec.Command}
+	default:
+		// Image entrypoint (fraglet-entrypoint for mounted fraglets) + optional args.
+		cfg.Cmd = spec.Args
+	}
+
+	binds, copies := splitVolumes(volumes, copyFiles)
+	for _, vol := range binds {
+		bind := vol.HostPath + ":" + vol.ContainerPath
+		if !vol.Writable {
+			bind += ":ro"
+		}
+		cfg.HostConfig.Binds = append(cfg.HostConfig.Binds, bind)
+	}
+	return cfg, copies, cleanup, nil
+}
+
+// applyAPILimits sets the HostConfig resource fields for l; runAPIStreaming validates l first.
+func applyAPILimits(hc *dockerapi.HostConfig, l ResourceLimits) {
+	hc.Memory, _ = l.MemoryBytes()
+	hc.NanoCpus, _ = l.NanoCPUs()
+	if l.PidsLimit > 0 {
+		pids := l.PidsLimit
+		hc.PidsLimit = &pids
+	}
+	for _, s := range l.Ulimits {
+		u, _ := parseUlimit(s)
+		hc.Ulimits = append(hc.Ulimits, dockerapi.Ulimit{Name: u.Name, Soft: u.Soft, Hard: u.Hard})
+	}
+}
+
+// stopAPIContainer stops a container on a fresh context (the run's is already done), falling back
+// to a forced removal when the stop request fails.
+func stopAPIContainer(c *dockerapi.Client, id string, grace time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), grace+removeTimeout)
+	defer cancel()
+	if err := c.ContainerStop(ctx, id, grace); err != nil {
+		_ = c.ContainerRemove(ctx, id, true)
+	}
+}
+
+// resizeAPIContainer applies each terminal size to the running container until done closes.
+func resizeAPIContainer(c *dockerapi.Client, id string, sizes <-chan TermSize, done <-chan struct{}) {
+	if sizes == nil {
+		return
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case size, ok := <-sizes:
+			if !ok {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+			_ = c.ContainerResize(ctx, id, size.Rows, size.Cols)
+			cancel()
+		}
+	}
+}
+
+// killAPIContainer signals a container on a fresh context; a container that already exited is fine.
+func killAPIContainer(c *dockerapi.Client, id, signal string) {
+	ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+	defer cancel()
+	_ = c.ContainerKill(ctx, id, signal)
+}
+
+// ensureImageAPI checks if the image exists locally; if not, it pulls it for the given platform.
+func ensureImageAPI(ctx context.Context, c *dockerapi.Client, image, platform string) error {
+	_, err := c.ImageInspect(ctx, image)
+	if err == nil {
+		return nil // already present
+	}
+	if !errors.Is(err, dockerapi.ErrNotFound) {
+		return errorf(ErrDaemon, "failed to inspect image %s: %w", image, err)
+	}
+	if err := c.ImagePull(ctx, image, platform); err != nil {
+		return errorf(ErrImagePull, "failed to pull image %s: %w", image, err)
+	}
+	return nil
+}
+
+// runAPIStreaming executes spec through the Engine API: create → attach → wait → start,
+// then demultiplexes output and removes the container once it exits.
+func runAPIStreaming(ctx context.Context, c *dockerapi.Client, spec RunSpec) (*StreamingResult, error) {
+	if spec.Container == "" {
+		return nil, fmt.Errorf("docker runner requires container image")
+	}
+	if err := spec.Limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Default platform to linux/amd64 unless explicitly set
+	platform := spec.Platform
+	if platform == "" {
+		platform = "linux/amd64"
+	}
+
+	var timings PhaseTimings
+	phase := time.Now()
+	if err := ensureImageAPI(ctx, c, spec.Container, platform); err != nil {
+		return nil, err
+	}
+	timings.Pull = time.Since(phase)
+	phase = time.Now()
+
+	cfg, copies, cleanup, err := apiContainerConfig(spec, spec.Transport.copyFiles(c.Host()))
+	if err != nil {
+		return nil, err
+	}
+
+	bound := len(cfg.HostConfig.Binds) > 0
+	id, err := c.ContainerCreate(ctx, newContainerName(), platform, cfg)
+	if err != nil {
+		cleanup()
+		return nil, bindHint(errorf(ErrDaemon, "failed to create container: %w", err), bound)
+	}
+	remove := func() {
+		rmCtx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+		defer cancel()
+		_ = c.ContainerRemove(rmCtx, id, true)
+		cleanup()
+	}
+
+	// The daemon may not see host paths: upload the files before the entrypoint looks for them.
+	if len(copies) > 0 {
+		archive, err := volumesArchive(copies)
+		if err != nil {
+			remove()
+			return nil, fmt.Errorf("failed to pack files for container: %w", err)
+		}
+		if err := c.CopyToContainer(ctx, id, "/", archive); err != nil {
+			remove()
+			return nil, errorf(ErrDaemon, "failed to copy files into container: %w", err)
+		}
+	}
+
+	stream, err := c.ContainerAttach(ctx, id, cfg.AttachStdin)
+	if err != nil {
+		remove()
+		return nil, errorf(ErrDaemon, "failed to attach to container: %w", err)
+	}
+	wait, err := c.ContainerWait(ctx, id)
+	if err != nil {
+		stream.Close()
+		remove()
+		return nil, errorf(ErrDaemon, "failed to wait for container: %w", err)
+	}
+	if err := c.ContainerStart(ctx, id); err != nil {
+		stream.Close()
+		remove()
+		return nil, bindHint(startError(err), bound)
+	}
+
+	stdoutChan := make(chan string, 10)
+	stderrChan := make(chan string, 10)
+	doneChan := make(chan error, 1)
+	exitCodeChan := make(chan int, 1)
+	infoChan := make(chan RunInfo, 1)
+	timings.Create = time.Since(phase)
+	phase = time.Now()
+
+	var stdout, stderr io.Writer = chanWriter(stdoutChan), chanWriter(stderrChan)
+	if spec.Stdout != nil {
+		stdout = spec.Stdout
+	}
+	if spec.Stderr != nil {
+		stderr = spec.Stderr
+	}
+
+	if cfg.AttachStdin {
+		stdin := spec.StdinReader
+		if stdin == nil {
+			stdin = bytes.NewBufferString(spec.Stdin)
+		}
+		go func() {
+			_, _ = io.Copy(stream, stdin)
+			_ = stream.CloseWrite()
+		}()
+	}
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		if cfg.Tty {
+			_, _ = io.Copy(stdout, stream) // a TTY stream is raw, not multiplexed
+		} else {
+			_ = dockerapi.Demux(stream, stdout, stderr)
+		}
+		close(stdoutChan)
+		close(stderrChan)
+	}()
+	if cfg.Tty {
+		go resizeAPIContainer(c, id, spec.TermSizes, outputDone)
+	}
+	forwardSignals(spec, outputDone,
+		func(sig os.Signal) { killAPIContainer(c, id, signalArg(sig)) },
+		func() { killAPIContainer(c, id, "KILL") })
+
+	go func() {
+		res, err := wait()
+		if err != nil {
+			// Wait failed (typically ctx cancelled): stop the container with a grace period so
+			// it does not outlive the run; that also ends the attach stream.
+			stopAPIContainer(c, id, spec.stopGrace())
+		}
+		<-outputDone
+		stream.Close()
+		timings.Execute = time.Since(phase)
+
+		// Inspect before removal: the exit code after a stop, and whether the kernel OOM-killed it.
+		code := -1
+		if err == nil {
+			code = res.StatusCode
+		}
+		termination := TerminationReason("")
+		inspectCtx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+		if info, inspectErr := c.ContainerInspect(inspectCtx, id); inspectErr == nil && !info.State.Running {
+			code = info.State.ExitCode
+			if info.State.OOMKilled {
+				termination = TerminationOOMKilled
+			}
+		}
+		cancel()
+		if termination == "" {
+			termination = terminationForExit(code)
+		}
+		if err != nil && ctx.Err() == nil {
+			err, termination = wrapErr(ErrDaemon, err), TerminationInfraError
+		}
+		remove()
+		exitCodeChan <- code
+		infoChan <- RunInfo{Timings: timings, Termination: termination}
+		doneChan <- err
+		close(exitCodeChan)
+		close(infoChan)
+		close(doneChan)
+	}()
+
+	return &StreamingResult{
+		Stdout:   stdoutChan,
+		Stderr:   stderrChan,
+		Done:     doneChan,
+		ExitCode: exitCodeChan,
+		Info:     infoChan,
+	}, nil
+}
@@ -0,0 +1,375 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+	"github.com/ofthemachine/fraglet/pkg/dockerapi/dockerapitest"
+)
+
+func TestDockerRunner_API_Run(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		in, _ := io.ReadAll(stdin)
+		fmt.Fprintf(stdout, "stdin=%s args=%s", in, strings.Join(c.Config.Cmd, ","))
+		fmt.Fprint(stderr, "warn")
+		return 3
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	spec := RunSpec{
+		Container:   "100hellos/python:latest",
+		Env:         []string{"FRAGLET_MODE=main"},
+		Args:        []string{"a1"},
+		Stdin:       "hello",
+		NetworkMode: "none",
+		Volumes:     []VolumeMount{{HostPath: "/tmp/code", ContainerPath: "/FRAGLET"}},
+	}
+	result, err := r.Run(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stdout != "stdin=hello args=a1" {
+		t.Errorf("stdout = %q", result.Stdout)
+	}
+	if result.Stderr != "warn" {
+		t.Errorf("stderr = %q", result.Stderr)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", result.ExitCode)
+	}
+	if result.Termination != TerminationExited {
+		t.Errorf("termination = %q, want %q", result.Termination, TerminationExited)
+	}
+	if result.Timings.Pull <= 0 || result.Timings.Create <= 0 || result.Timings.Execute <= 0 {
+		t.Errorf("phases not timed: %+v", result.Timings)
+	}
+
+	// Image was missing, so it was pulled for the default platform.
+	if len(srv.Pulled) != 1 || srv.Pulled[0] != "100hellos/python:latest" {
+		t.Errorf("pulled = %v", srv.Pulled)
+	}
+	// Container removed after exit.
+	if n := len(srv.ContainerList()); n != 0 {
+		t.Errorf("%d containers left behind", n)
+	}
+	// Lifecycle order: create → attach → wait → start → remove.
+	var lifecycle []string
+	for _, req := range srv.RequestLog() {
+		if strings.HasPrefix(req, "POST /containers/") || strings.HasPrefix(req, "DELETE /containers/") {
+			parts := strings.Split(req, "/")
+			lifecycle = append(lifecycle, strings.Fields(req)[0]+" "+parts[len(parts)-1])
+		}
+	}
+	want := []string{"POST create", "POST attach", "POST wait", "POST start", "DELETE c0001"}
+	if !slices.Equal(lifecycle, want) {
+		t.Errorf("lifecycle = %v, want %v", lifecycle, want)
+	}
+}
+
+func TestApiContainerConfig_MapsRunSpec(t *testing.T) {
+	cfg, copies, cleanup, err := apiContainerConfig(RunSpec{
+		Container:   "img",
+		Env:         []string{"A=1"},
+		SecretEnv:   []string{"TOKEN=s3cr3t"},
+		WorkDir:     "/work",
+		NetworkMode: "none",
+		Volumes: []VolumeMount{
+			{HostPath: "/h/ro", ContainerPath: "/ro"},
+			{HostPath: "/h/rw", ContainerPath: "/rw", Writable: true},
+		},
+		Args:   []string{"x"},
+		Limits: ResourceLimits{Memory: "256m", CPUs: "0.5", PidsLimit: 64, Ulimits: []string{"nofile=1024:2048"}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	hc := cfg.HostConfig
+	if hc.Memory != 256<<20 || hc.NanoCpus != 5e8 || hc.PidsLimit == nil || *hc.PidsLimit != 64 {
+		t.Errorf("limits not mapped: memory=%d nanocpus=%d pids=%v", hc.Memory, hc.NanoCpus, hc.PidsLimit)
+	}
+	if len(hc.Ulimits) != 1 || hc.Ulimits[0] != (dockerapi.Ulimit{Name: "nofile", Soft: 1024, Hard: 2048}) {
+		t.Errorf("ulimits = %+v", hc.Ulimits)
+	}
+	if !slices.Equal(cfg.HostConfig.Binds, []string{"/h/ro:/ro:ro", "/h/rw:/rw"}) {
+		t.Errorf("binds = %v", cfg.HostConfig.Binds)
+	}
+	if copies != nil {
+		t.Errorf("copies = %v, want none when binding", copies)
+	}
+	if !slices.Equal(cfg.Env, []string{"A=1", "TOKEN=s3cr3t"}) {
+		t.Errorf("env = %v", cfg.Env)
+	}
+	if cfg.HostConfig.NetworkMode != "none" || cfg.WorkingDir != "/work" {
+		t.Errorf("network/workdir not mapped: %+v", cfg)
+	}
+	if !slices.Equal(cfg.HostConfig.CapDrop, []string{"ALL"}) || !slices.Equal(cfg.HostConfig.SecurityOpt, []string{"no-new-privileges"}) {
+		t.Errorf("hardening not applied: %+v", cfg.HostConfig)
+	}
+	if cfg.AttachStdin || cfg.OpenStdin {
+		t.Error("stdin must not be attached without input")
+	}
+	if !slices.Equal(cfg.Cmd, []string{"x"}) || cfg.Entrypoint != nil {
+		t.Errorf("cmd/entrypoint = %v/%v", cfg.Cmd, cfg.Entrypoint)
+	}
+}
+
+func TestDockerRunner_API_CancelStopsContainer(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, "started")
+		<-c.Stopping()
+		return 143
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	result, err := r.Run(ctx, RunSpec{Container: "img"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if result.Termination != TerminationCancelled {
+		t.Errorf("termination = %q, want %q", result.Termination, TerminationCancelled)
+	}
+	if result.Stdout != "started" || result.ExitCode != 143 {
+		t.Errorf("stdout = %q, exit = %d", result.Stdout, result.ExitCode)
+	}
+	if !slices.ContainsFunc(srv.RequestLog(), func(r string) bool { return strings.HasSuffix(r, "/stop") }) {
+		t.Errorf("container was not stopped: %v", srv.RequestLog())
+	}
+	if n := len(srv.ContainerList()); n != 0 {
+		t.Errorf("%d containers left behind", n)
+	}
+}
+
+func TestDockerRunner_API_TimeoutKillsAfterGrace(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	release := make(chan struct{})
+	defer close(release)
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		<-release // ignores the stop signal
+		return 0
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := r.Run(ctx, RunSpec{Container: "img", StopGrace: time.Second})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout wrapping deadline exceeded", err)
+	}
+	if result.Termination != TerminationTimeout || result.ExitCode != 137 {
+		t.Errorf("termination = %q, exit = %d; want timeout, 137", result.Termination, result.ExitCode)
+	}
+	if n := len(srv.ContainerList()); n != 0 {
+		t.Errorf("%d containers left behind", n)
+	}
+}
+
+func TestDockerRunner_API_SubSecondGrace(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		<-c.Stopping()
+		time.Sleep(100 * time.Millisecond) // cleans up, within the grace period
+		return 3
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, _ := r.Run(ctx, RunSpec{Container: "img", StopGrace: 500 * time.Millisecond})
+	if result.ExitCode != 3 {
+		t.Errorf("exit = %d, want 3: a sub-second grace must not kill at once", result.ExitCode)
+	}
+}
+
+func TestDockerRunner_API_Termination(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		switch c.Config.Cmd[0] {
+		case "oom":
+			c.OOMKill()
+			return 137
+		case "kill":
+			return 143
+		}
+		return 1
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	for arg, want := range map[string]TerminationReason{
+		"oom":  TerminationOOMKilled,
+		"kill": TerminationSignaled,
+		"fail": TerminationExited,
+	} {
+		result, err := r.Run(context.Background(), RunSpec{Container: "img", Args: []string{arg}})
+		if err != nil {
+			t.Fatalf("%s: %v", arg, err)
+		}
+		if result.Termination != want {
+			t.Errorf("%s: termination = %q, want %q", arg, result.Termination, want)
+		}
+	}
+}
+
+func TestDockerRunner_API_InfraError(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	r := &dockerRunner{client: srv.Client(t)}
+
+	result, err := r.Run(context.Background(), RunSpec{Container: "img", Limits: ResourceLimits{Memory: "lots"}})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.Termination != TerminationInfraError || result.ExitCode != -1 {
+		t.Errorf("termination = %q, exit = %d; want infra-error, -1", result.Termination, result.ExitCode)
+	}
+}
+
+func TestDockerRunner_API_PullFailure(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.PullError = "manifest unknown"
+	r := &dockerRunner{client: srv.Client(t)}
+
+	_, err := r.Run(context.Background(), RunSpec{Container: "missing:latest"})
+	if !errors.Is(err, ErrImagePull) {
+		t.Fatalf("err = %v, want ErrImagePull", err)
+	}
+}
+
+func TestDockerRunner_API_ForwardsSignals(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, "ready")
+		<-c.Stopping()
+		fmt.Fprint(stdout, " handled")
+		return 130
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	signals := make(chan os.Signal, 1)
+	time.AfterFunc(200*time.Millisecond, func() { signals <- syscall.SIGINT })
+	result, err := r.Run(context.Background(), RunSpec{Container: "img", Signals: signals})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stdout != "ready handled" || result.ExitCode != 130 {
+		t.Errorf("stdout = %q, exit = %d", result.Stdout, result.ExitCode)
+	}
+	if !slices.ContainsFunc(srv.RequestLog(), func(r string) bool { return strings.HasSuffix(r, "/kill") }) {
+		t.Errorf("signal was not sent to the container: %v", srv.RequestLog())
+	}
+}
+
+func TestDockerRunner_API_KillsAfterSignalGrace(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan *dockerapitest.Container, 1)
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		started <- c
+		<-release // ignores the signal
+		return 0
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+	result, err := r.Run(context.Background(), RunSpec{Container: "img", Signals: signals, StopGrace: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 137 || result.Termination != TerminationSignaled {
+		t.Errorf("exit = %d, termination = %q; want 137, signaled", result.ExitCode, result.Termination)
+	}
+	if got := srv.Signals(<-started); !slices.Equal(got, []string{"15", "KILL"}) {
+		t.Errorf("signals = %v, want [15 KILL]", got)
+	}
+}
+
+func TestDockerRunner_API_TTY(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	started := make(chan *dockerapitest.Container, 1)
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		started <- c
+		in, _ := io.ReadAll(stdin)
+		fmt.Fprintf(stdout, "tty=%t ", c.Config.Tty)
+		fmt.Fprintf(stderr, "in=%s", in)
+		time.Sleep(100 * time.Millisecond) // let the resize land
+		return 0
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	sizes := make(chan TermSize, 1)
+	sizes <- TermSize{Rows: 24, Cols: 80}
+	result, err := r.Run(context.Background(), RunSpec{Container: "img", TTY: true, TermSizes: sizes, Stdin: "typed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A TTY has a single raw output stream: stderr arrives on stdout.
+	if result.Stdout != "tty=true in=typed" || result.Stderr != "" {
+		t.Errorf("stdout = %q, stderr = %q", result.Stdout, result.Stderr)
+	}
+	if got := srv.Sizes(<-started); !slices.Equal(got, []string{"24x80"}) {
+		t.Errorf("sizes = %v, want [24x80]", got)
+	}
+}
+
+func TestDockerRunner_API_CopyTransport(t *testing.T) {
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{ID: "sha256:img"}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		code, _ := c.File("/FRAGLET")
+		fmt.Fprintf(stdout, "binds=%v code=%s", c.Config.HostConfig.Binds, code)
+		return 0
+	}
+	r := &dockerRunner{client: srv.Client(t)}
+
+	code := filepath.Join(t.TempDir(), "fraglet")
+	if err := os.WriteFile(code, []byte("print(1)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	result, err := r.Run(context.Background(), RunSpec{
+		Container: "img",
+		Transport: TransportCopy,
+		Volumes: []VolumeMount{
+			{HostPath: code, ContainerPath: "/FRAGLET"},
+			{HostPath: out, ContainerPath: "/out", Writable: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fraglet is uploaded before start; a writable mount can only be bound.
+	if want := "binds=[" + out + ":/out] code=print(1)"; result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+	var order []string
+	for _, req := range srv.RequestLog() {
+		if strings.Contains(req, "/archive") || strings.HasSuffix(req, "/start") {
+			order = append(order, strings.Fields(req)[0])
+		}
+	}
+	if !slices.Equal(order, []string{"PUT", "POST"}) {
+		t.Errorf("archive/start order = %v, want [PUT POST]", order)
+	}
+}
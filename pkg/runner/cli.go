@@ -0,0 +1,318 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dockerRunBuilder constructs "<bin> run ..." argv in a consistent order:
+// base (run, --rm, [-i when stdin], platform, hardening) → opts → image → args.
+// Use attachStdin true only when spec has stdin; otherwise the container exits when the program ends instead of waiting for stdin.
+// The argv is shared by every docker-compatible CLI (docker, podman).
+type dockerRunBuilder struct {
+	args []string
+}
+
+func newDockerRunBuilder(platform string, attachStdin bool) *dockerRunBuilder {
+	return newRunBuilder("docker", platform, attachStdin)
+}
+
+func newRunBuilder(bin, platform string, attachStdin bool) *dockerRunBuilder {
+	args := []string{bin, "run", "--rm"}
+	if attachStdin {
+		args = append(args, "-i")
+	}
+	args = append(args, "--platform", platform, "--cap-drop=all", "--security-opt=no-new-privileges")
+	return &dockerRunBuilder{args: args}
+}
+
+// readOnly: true = :ro mount (default for secure-by-default); false = read-write.
+func (b *dockerRunBuilder) Volume(hostPath, containerPath string, readOnly bool) *dockerRunBuilder {
+	spec := fmt.Sprintf("%s:%s", hostPath, containerPath)
+	if readOnly {
+		spec += ":ro"
+	}
+	b.args = append(b.args, "-v", spec)
+	return b
+}
+
+func (b *dockerRunBuilder) Volumes(volumes []VolumeMount) *dockerRunBuilder {
+	for _, vol := range volumes {
+		b.Volume(vol.HostPath, vol.ContainerPath, !vol.Writable) // read-only by default
+	}
+	return b
+}
+
+// Name sets the container name so the run can be stopped by name when cancelled.
+func (b *dockerRunBuilder) Name(name string) *dockerRunBuilder {
+	b.args = append(b.args, "--name", name)
+	return b
+}
+
+// TTY allocates a pseudo-terminal (-t). The CLI puts its own terminal in raw mode and follows
+// window resizes when its stdin is that terminal.
+func (b *dockerRunBuilder) TTY(tty bool) *dockerRunBuilder {
+	if tty {
+		b.args = append(b.args, "-t")
+	}
+	return b
+}
+
+// Network sets the container network mode (docker --network), e.g. "none" to
+// disable all networking. No-op when mode is empty (docker's default bridge).
+func (b *dockerRunBuilder) Network(mode string) *dockerRunBuilder {
+	if mode != "" {
+		b.args = append(b.args, "--network", mode)
+	}
+	return b
+}
+
+// Limits adds --memory, --cpus, --pids-limit and --ulimit for every set field.
+// Values are passed through verbatim; callers validate them first.
+func (b *dockerRunBuilder) Limits(l ResourceLimits) *dockerRunBuilder {
+	if l.Memory != "" {
+		b.args = append(b.args, "--memory", l.Memory)
+	}
+	if l.CPUs != "" {
+		b.args = append(b.args, "--cpus", l.CPUs)
+	}
+	if l.PidsLimit > 0 {
+		b.args = append(b.args, "--pids-limit", strconv.FormatInt(l.PidsLimit, 10))
+	}
+	for _, u := range l.Ulimits {
+		b.args = append(b.args, "--ulimit", u)
+	}
+	return b
+}
+
+func (b *dockerRunBuilder) Env(env []string) *dockerRunBuilder {
+	for _, e := range env {
+		b.args = append(b.args, "-e", e)
+	}
+	return b
+}
+
+// SecretEnv adds "-e NAME" for each NAME=value: the CLI reads the value from its own environment
+// (see cliEnv), so it never appears in an argv.
+func (b *dockerRunBuilder) SecretEnv(env []string) *dockerRunBuilder {
+	for _, e := range env {
+		name, _, _ := strings.Cut(e, "=")
+		b.args = append(b.args, "-e", name)
+	}
+	return b
+}
+
+// cliEnv is the environment for a CLI invocation that forwards secrets by name, nil (inherit) without secrets.
+func cliEnv(secrets []string) []string {
+	if len(secrets) == 0 {
+		return nil
+	}
+	return append(os.Environ(), secrets...)
+}
+
+func (b *dockerRunBuilder) WorkDir(dir string) *dockerRunBuilder {
+	if dir != "" {
+		b.args = append(b.args, "-w", dir)
+	}
+	return b
+}
+
+func (b *dockerRunBuilder) Entrypoint(ep string) *dockerRunBuilder {
+	b.args = append(b.args, "--entrypoint", ep)
+	return b
+}
+
+func (b *dockerRunBuilder) Image(image string) *dockerRunBuilder {
+	b.args = append(b.args, image)
+	return b
+}
+
+func (b *dockerRunBuilder) Args(a ...string) *dockerRunBuilder {
+	b.args = append(b.args, a...)
+	return b
+}
+
+func (b *dockerRunBuilder) Build() []string {
+	return b.args
+}
+
+// cliAvailable reports whether bin answers "<bin> version" (CLI present and daemon/service reachable).
+func cliAvailable(bin string) bool {
+	return cliProbe(bin) == nil
+}
+
+// runCLIStreaming executes spec through a docker-compatible CLI (bin is "docker" or "podman").
+// image is the reference passed to the CLI; callers may qualify spec.Container first.
+func runCLIStreaming(ctx context.Context, bin, image string, spec RunSpec) (*StreamingResult, error) {
+	if spec.Container == "" {
+		return nil, fmt.Errorf("%s runner requires container image", bin)
+	}
+	if err := spec.Limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Default platform to linux/amd64 unless explicitly set
+	platform := spec.Platform
+	if platform == "" {
+		platform = "linux/amd64"
+	}
+
+	// Ensure image exists locally; if not, pull it for the requested platform
+	pullStart := time.Now()
+	if err := ensureImage(ctx, bin, image, platform); err != nil {
+		return nil, err
+	}
+	timings := PhaseTimings{Pull: time.Since(pullStart)}
+
+	var args []string
+	var tempFile string
+	var cleanup func()
+
+	allEnv := spec.Env
+
+	// Without a daemon on this machine the files are copied in between create and start.
+	copyFiles := spec.Transport.copyFiles(cliHost(bin))
+	binds, copies := splitVolumes(spec.Volumes, copyFiles)
+
+	name := newContainerName()
+	attachStdin := spec.StdinReader != nil || spec.Stdin != ""
+	base := newRunBuilder(bin, platform, attachStdin).TTY(spec.TTY).Name(name).Network(spec.NetworkMode).Limits(spec.Limits)
+	withCommon := func(b *dockerRunBuilder) *dockerRunBuilder {
+		return b.Env(allEnv).SecretEnv(spec.SecretEnv).WorkDir(spec.WorkDir).Volumes(binds)
+	}
+
+	switch {
+	case len(spec.Volumes) > 0 && spec.Command == "" && spec.Entrypoint == "":
+		// Volumes only: fraglet-entrypoint containers; default entrypoint runs mounted fraglet.
+		args = withCommon(base).Image(image).Args(spec.Args...).Build()
+	case spec.Entrypoint != "" && spec.Command != "":
+		// Entrypoint + command: write command to temp file, mount it, run via entrypoint.
+		var err error
+		tempFile, cleanup, err = writeTempScript(spec.Command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp script: %w", err)
+		}
+		b := base.Entrypoint(spec.Entrypoint)
+		if copyFiles {
+			copies = append(copies, VolumeMount{HostPath: tempFile, ContainerPath: "/tmp/script"})
+		} else {
+			b = b.Volume(tempFile, "/tmp/script", true)
+		}
+		args = b.Env(allEnv).SecretEnv(spec.SecretEnv).WorkDir(spec.WorkDir).Volumes(binds).
+			Image(image).Args("/tmp/script").Args(spec.Args...).Build()
+	case spec.Entrypoint != "":
+		// Entrypoint only: no command body.
+		args = withCommon(base.Entrypoint(spec.Entrypoint)).Image(image).Args(spec.Args...).Build()
+	case spec.Command != "":
+		// Command via sh -c; args don't apply.
+		args = withCommon(base).Image(image).Args("sh", "-c", spec.Command).Build()
+	default:
+		// Plain run: image + optional args.
+		args = withCommon(base).Image(image).Args(spec.Args...).Build()
+	}
+
+	bound := len(binds) > 0 || tempFile != "" && !copyFiles
+	if len(copies) > 0 {
+		createStart := time.Now()
+		var err error
+		if args, err = createAndCopy(ctx, bin, name, args, copies, attachStdin, cliEnv(spec.SecretEnv)); err != nil {
+			if cleanup != nil {
+				cleanup()
+			}
+			return nil, err
+		}
+		timings.Create = time.Since(createStart)
+	}
+
+	// On cancellation stop the container itself rather than only killing the CLI client, which
+	// would leave the container running. WaitDelay kills the client if it still hangs afterwards.
+	cliCmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cliCmd.Env = cliEnv(spec.SecretEnv)
+	cliCmd.Cancel = func() error {
+		stopCLIContainer(bin, name, spec.stopGrace())
+		return nil
+	}
+	cliCmd.WaitDelay = spec.stopGrace() + removeTimeout
+
+	// Signals go to the CLI client, which proxies them to the container (--sig-proxy, on by
+	// default); if the program ignores them the container itself is killed.
+	streaming, err := startProcess(cliCmd, spec, processHooks{
+		timings: timings,
+		classify: func(code int, stderr string) error {
+			return bindHint(cliExitError(bin, code, stderr), bound && code == exitDaemon)
+		},
+		kill:    func() { killCLIContainer(bin, name) },
+		cleanup: cleanup,
+	})
+	if err != nil {
+		return nil, errorf(ErrDaemon, "%s: %w", bin, err)
+	}
+	return streaming, nil
+}
+
+// createAndCopy creates the container described by the "<bin> run" argv, copies files into it
+// and returns the "<bin> start" argv that attaches to it like run would. The container is
+// removed again if anything fails; --rm removes it after start as usual.
+func createAndCopy(ctx context.Context, bin, name string, runArgs []string, files []VolumeMount, attachStdin bool, env []string) ([]string, error) {
+	create := append([]string{bin, "create"}, runArgs[2:]...)
+	createCmd := exec.CommandContext(ctx, create[0], create[1:]...)
+	createCmd.Env = env
+	if out, err := createCmd.CombinedOutput(); err != nil {
+		return nil, errorf(ErrDaemon, "%s create failed: %v\n%s", bin, err, out)
+	}
+	for _, f := range files {
+		if out, err := exec.CommandContext(ctx, bin, "cp", f.HostPath, name+":"+f.ContainerPath).CombinedOutput(); err != nil {
+			removeCLIContainer(bin, name)
+			return nil, errorf(ErrDaemon, "%s cp %s failed: %v\n%s", bin, f.HostPath, err, out)
+		}
+	}
+	start := []string{bin, "start", "-a"}
+	if attachStdin {
+		start = append(start, "-i")
+	}
+	return append(start, name), nil
+}
+
+// removeCLIContainer force-removes the named container on a fresh context.
+func removeCLIContainer(bin, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+	defer cancel()
+	_ = exec.CommandContext(ctx, bin, "rm", "-f", name).Run()
+}
+
+// stopCLIContainer stops the named container with a grace period and removes it. It runs on a
+// fresh context because the run's context is already done.
+func stopCLIContainer(bin, name string, grace time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), grace+removeTimeout)
+	defer cancel()
+	// stop -t takes whole seconds; round up so a sub-second grace is not a kill.
+	secs := int((grace + time.Second - 1) / time.Second)
+	_ = exec.CommandContext(ctx, bin, "stop", "-t", strconv.Itoa(secs), name).Run()
+	_ = exec.CommandContext(ctx, bin, "rm", "-f", name).Run()
+}
+
+// killCLIContainer kills the named container outright; --rm then removes it.
+func killCLIContainer(bin, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+	defer cancel()
+	_ = exec.CommandContext(ctx, bin, "kill", name).Run()
+}
+
+// ensureImage checks if the image exists locally; if not, it pulls it for the given platform.
+func ensureImage(ctx context.Context, bin, image, platform string) error {
+	inspect := exec.CommandContext(ctx, bin, "image", "inspect", image)
+	if err := inspect.Run(); err == nil {
+		return nil // already present
+	}
+	// Pull with platform
+	pull := exec.CommandContext(ctx, bin, "pull", "--platform", platform, image)
+	if out, err := pull.CombinedOutput(); err != nil {
+		return errorf(ErrImagePull, "failed to pull image %s: %v\n%s", image, err, string(out))
+	}
+	return nil
+}
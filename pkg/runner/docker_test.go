@@ -27,6 +27,32 @@ func TestDockerRunBuilder_Network(t *testing.T) {
 	}
 }
 
+func TestDockerRunBuilder_TTY(t *testing.T) {
+	got := newDockerRunBuilder("linux/amd64", true).TTY(true).Image("img").Build()
+	if !slices.Contains(got, "-t") || !slices.Contains(got, "-i") {
+		t.Fatalf("expected -i and -t, got: %v", got)
+	}
+	if slices.Index(got, "-t") > slices.Index(got, "img") {
+		t.Fatalf("-t must precede the image: %v", got)
+	}
+	if got := newDockerRunBuilder("linux/amd64", false).TTY(false).Image("img").Build(); slices.Contains(got, "-t") {
+		t.Fatalf("TTY(false) must not add -t: %v", got)
+	}
+}
+
+func TestDockerRunBuilder_SecretEnv(t *testing.T) {
+	got := newDockerRunBuilder("linux/amd64", false).Env([]string{"A=1"}).SecretEnv([]string{"TOKEN=s3cr3t"}).Image("img").Build()
+	if strings.Contains(strings.Join(got, " "), "s3cr3t") {
+		t.Fatalf("secret value in argv: %v", got)
+	}
+	if i := slices.Index(got, "TOKEN"); i < 1 || got[i-1] != "-e" {
+		t.Fatalf("expected -e TOKEN, got: %v", got)
+	}
+	if env := cliEnv([]string{"TOKEN=s3cr3t"}); !slices.Contains(env, "TOKEN=s3cr3t") || cliEnv(nil) != nil {
+		t.Fatalf("cliEnv = %v", env)
+	}
+}
+
 func TestDockerRunner_Available(t *testing.T) {
 	r := &dockerRunner{}
 	available := r.Available()
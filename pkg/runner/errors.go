@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors a run can fail with, kept apart from the program's own exit status (RunResult.ExitCode,
+// which is only meaningful when Run returns a nil error). Runners wrap the underlying cause, so
+// test with errors.Is.
+var (
+	ErrImagePull  = errors.New("image pull failed")
+	ErrDaemon     = errors.New("container engine error")
+	ErrEntrypoint = errors.New("container entrypoint could not be run")
+	ErrTimeout    = errors.New("run timed out")
+	ErrCancelled  = errors.New("run cancelled")
+)
+
+// Docker's documented "docker run" exit statuses for failures that happen before the program runs.
+const (
+	exitDaemon        = 125 // the daemon could not create or start the container
+	exitNotExecutable = 126 // the command could not be invoked
+	exitNotFound      = 127 // the command could not be found
+)
+
+// runError tags an error with one of the sentinel kinds without changing its message.
+type runError struct {
+	kind error
+	err  error
+}
+
+func (e *runError) Error() string   { return e.err.Error() }
+func (e *runError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// wrapErr tags err with kind; nil stays nil.
+func wrapErr(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &runError{kind: kind, err: err}
+}
+
+// errorf formats an error tagged with kind.
+func errorf(kind error, format string, args ...any) error {
+	return &runError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// Is lets errors.Is(err, ErrDaemon) match when no backend could be reached.
+func (e *NoRunnerError) Is(target error) bool {
+	return target == ErrDaemon
+}
+
+// ErrorCategory names the kind of a run error for machine consumers: "timeout", "cancelled",
+// "image_pull", "daemon" or "entrypoint"; "" when err is nil or of no known kind.
+func ErrorCategory(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrCancelled):
+		return "cancelled"
+	case errors.Is(err, ErrImagePull):
+		return "image_pull"
+	case errors.Is(err, ErrEntrypoint):
+		return "entrypoint"
+	case errors.Is(err, ErrDaemon):
+		return "daemon"
+	}
+	return ""
+}
+
+// ociErrors are what docker and podman print when the runtime could not invoke the container's
+// command, as opposed to the command itself exiting 126 or 127.
+var ociErrors = []string{"OCI runtime", "OCI permission denied"}
+
+// cliExitError maps the "docker run" statuses reserved for pre-start failures onto typed errors;
+// nil for any other code, which is the program's own. 126 and 127 count as entrypoint failures
+// only when stderr, the tail of what the CLI printed, carries the runtime's error; a program
+// that exits 126 or 127 itself (a shell fraglet calling a missing command) keeps its code. A
+// program that itself exits 125 is indistinguishable through the CLI; the Engine API backend
+// reports those failures directly.
+func cliExitError(bin string, code int, stderr string) error {
+	switch code {
+	case exitDaemon:
+		return errorf(ErrDaemon, "%s run failed (exit %d)", bin, code)
+	case exitNotExecutable, exitNotFound:
+		for _, s := range ociErrors {
+			if strings.Contains(stderr, s) {
+				return errorf(ErrEntrypoint, "%s run: container command could not be run (exit %d)", bin, code)
+			}
+		}
+	}
+	return nil
+}
+
+// startError classifies a failed container start: OCI runtime errors about the command are
+// entrypoint failures, everything else is the engine's.
+func startError(err error) error {
+	msg := err.Error()
+	for _, s := range []string{"executable file not found", "no such file or directory", "permission denied", "exec format error"} {
+		if strings.Contains(msg, s) {
+			return errorf(ErrEntrypoint, "failed to start container: %w", err)
+		}
+	}
+	return errorf(ErrDaemon, "failed to start container: %w", err)
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{errors.New("other"), ""},
+		{wrapErr(ErrTimeout, context.DeadlineExceeded), "timeout"},
+		{wrapErr(ErrCancelled, context.Canceled), "cancelled"},
+		{errorf(ErrImagePull, "failed to pull image x"), "image_pull"},
+		{fmt.Errorf("execution failed: %w", errorf(ErrDaemon, "boom")), "daemon"},
+		{cliExitError("docker", 127, "OCI runtime create failed"), "entrypoint"},
+		{&NoRunnerError{Image: "img"}, "daemon"},
+	}
+	for _, tt := range tests {
+		if got := ErrorCategory(tt.err); got != tt.want {
+			t.Errorf("ErrorCategory(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRunError_KeepsMessageAndCause(t *testing.T) {
+	err := wrapErr(ErrTimeout, context.DeadlineExceeded)
+	if err.Error() != context.DeadlineExceeded.Error() {
+		t.Errorf("message = %q", err.Error())
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrTimeout) {
+		t.Error("wrapped error must match both its kind and its cause")
+	}
+}
+
+func TestCliExitError(t *testing.T) {
+	const oci = `Error: crun: executable file not found in $PATH: No such file or directory: OCI runtime attempted to invoke a command that was not found`
+	for code, want := range map[int]error{0: nil, 1: nil, 124: nil, 125: ErrDaemon, 126: ErrEntrypoint, 127: ErrEntrypoint, 137: nil} {
+		got := cliExitError("podman", code, oci)
+		if (got == nil) != (want == nil) || (want != nil && !errors.Is(got, want)) {
+			t.Errorf("code %d: got %v, want %v", code, got, want)
+		}
+	}
+	if err := cliExitError("podman", 126, "Error: crun: open executable: Permission denied: OCI permission denied"); !errors.Is(err, ErrEntrypoint) {
+		t.Errorf("permission denied: got %v", err)
+	}
+	// The program's own 126 or 127, without the runtime's message, is passed through.
+	for _, code := range []int{126, 127} {
+		if err := cliExitError("docker", code, "sh: 1: frobnicate: not found\n"); err != nil {
+			t.Errorf("program exit %d: got %v, want nil", code, err)
+		}
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	var tb tailBuffer
+	tb.Write([]byte(strings.Repeat("x", stderrTailBytes)))
+	tb.Write([]byte("OCI runtime"))
+	if got := tb.String(); len(got) != stderrTailBytes || !strings.HasSuffix(got, "OCI runtime") {
+		t.Errorf("tail = %d bytes ending %q", len(got), got[len(got)-20:])
+	}
+}
+
+func TestStartError(t *testing.T) {
+	entry := startError(errors.New(`OCI runtime create failed: exec: "nope": executable file not found in $PATH`))
+	if !errors.Is(entry, ErrEntrypoint) {
+		t.Errorf("missing executable: %v is not ErrEntrypoint", entry)
+	}
+	if daemon := startError(errors.New("driver failed programming external connectivity")); !errors.Is(daemon, ErrDaemon) {
+		t.Errorf("%v is not ErrDaemon", daemon)
+	}
+}
+
+func TestBindHint(t *testing.T) {
+	err := bindHint(cliExitError("docker", exitDaemon, ""), true)
+	if !errors.Is(err, ErrDaemon) || !strings.Contains(err.Error(), "--transport=copy") {
+		t.Errorf("bound run: err = %v", err)
+	}
+	if err := bindHint(cliExitError("docker", exitDaemon, ""), false); strings.Contains(err.Error(), "--transport") {
+		t.Errorf("nothing bound: err = %v", err)
+	}
+	if err := bindHint(nil, true); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+}
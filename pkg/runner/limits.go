@@ -0,0 +1,299 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ResourceLimits bounds what a single run may consume. Zero values mean "no limit".
+// The same struct is read from veins.yml (per-vein defaults), set from fragletc flags and
+// validated on MCP run input.
+type ResourceLimits struct {
+	Memory    string   `yaml:"memory,omitempty" json:"memory,omitempty"`       // docker --memory, e.g. "512m", "1g"
+	CPUs      string   `yaml:"cpus,omitempty" json:"cpus,omitempty"`           // docker --cpus, e.g. "1.5"
+	PidsLimit int64    `yaml:"pids,omitempty" json:"pids,omitempty"`           // docker --pids-limit
+	Ulimits   []string `yaml:"ulimits,omitempty" json:"ulimits,omitempty"`     // docker --ulimit, e.g. "nofile=1024:2048"
+	MaxOutput string   `yaml:"maxOutput,omitempty" json:"maxOutput,omitempty"` // cap on stdout+stderr bytes, e.g. "1m"
+}
+
+// IsZero reports whether no limit is set.
+func (l ResourceLimits) IsZero() bool {
+	return l.Memory == "" && l.CPUs == "" && l.PidsLimit == 0 && len(l.Ulimits) == 0 && l.MaxOutput == ""
+}
+
+// Merge returns l with every non-zero field of override applied on top. Ulimits are merged by
+// name, so an override for one resource keeps l's limits on the others.
+func (l ResourceLimits) Merge(override ResourceLimits) ResourceLimits {
+	if override.Memory != "" {
+		l.Memory = override.Memory
+	}
+	if override.CPUs != "" {
+		l.CPUs = override.CPUs
+	}
+	if override.PidsLimit != 0 {
+		l.PidsLimit = override.PidsLimit
+	}
+	if len(override.Ulimits) > 0 {
+		l.Ulimits = mergeUlimits(l.Ulimits, override.Ulimits)
+	}
+	if override.MaxOutput != "" {
+		l.MaxOutput = override.MaxOutput
+	}
+	return l
+}
+
+// Validate checks every set field parses.
+func (l ResourceLimits) Validate() error {
+	if _, err := l.MemoryBytes(); err != nil {
+		return err
+	}
+	if _, err := l.NanoCPUs(); err != nil {
+		return err
+	}
+	if l.PidsLimit < 0 {
+		return fmt.Errorf("pids limit must not be negative, got %d", l.PidsLimit)
+	}
+	for _, u := range l.Ulimits {
+		if _, err := parseUlimit(u); err != nil {
+			return err
+		}
+	}
+	if _, err := l.MaxOutputBytes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Within returns an error naming the first field of l that exceeds the corresponding ceiling in max.
+// Unset ceilings are unbounded; unset fields in l are fine (the caller applies max as the default).
+func (l ResourceLimits) Within(max ResourceLimits) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	mem, _ := l.MemoryBytes()
+	maxMem, _ := max.MemoryBytes()
+	if mem > 0 && maxMem > 0 && mem > maxMem {
+		return fmt.Errorf("memory %s exceeds the allowed maximum %s", l.Memory, max.Memory)
+	}
+	cpus, _ := l.NanoCPUs()
+	maxCPUs, _ := max.NanoCPUs()
+	if cpus > 0 && maxCPUs > 0 && cpus > maxCPUs {
+		return fmt.Errorf("cpus %s exceeds the allowed maximum %s", l.CPUs, max.CPUs)
+	}
+	if l.PidsLimit > 0 && max.PidsLimit > 0 && l.PidsLimit > max.PidsLimit {
+		return fmt.Errorf("pids limit %d exceeds the allowed maximum %d", l.PidsLimit, max.PidsLimit)
+	}
+	maxUlimits := make(map[string]Ulimit, len(max.Ulimits))
+	for _, s := range max.Ulimits {
+		if u, err := parseUlimit(s); err == nil {
+			maxUlimits[u.Name] = u
+		}
+	}
+	for _, s := range l.Ulimits {
+		u, _ := parseUlimit(s)
+		m, ok := maxUlimits[u.Name]
+		if ok && (u.Soft > m.Soft || u.Hard > m.Hard) {
+			return fmt.Errorf("ulimit %s exceeds the allowed maximum %s", s, max.ulimit(u.Name))
+		}
+	}
+	out, _ := l.MaxOutputBytes()
+	maxOut, _ := max.MaxOutputBytes()
+	if out > 0 && maxOut > 0 && out > maxOut {
+		return fmt.Errorf("max output %s exceeds the allowed maximum %s", l.MaxOutput, max.MaxOutput)
+	}
+	return nil
+}
+
+// ulimit returns the Ulimits entry for name as written, or "".
+func (l ResourceLimits) ulimit(name string) string {
+	for _, s := range l.Ulimits {
+		if n, _, _ := strings.Cut(s, "="); n == name {
+			return s
+		}
+	}
+	return ""
+}
+
+// mergeUlimits returns base with each entry of override replacing base's entry of the same name
+// or, for a new name, appended.
+func mergeUlimits(base, override []string) []string {
+	out := slices.Clone(base)
+	for _, o := range override {
+		name, _, _ := strings.Cut(o, "=")
+		i := slices.IndexFunc(out, func(s string) bool {
+			n, _, _ := strings.Cut(s, "=")
+			return n == name
+		})
+		if i >= 0 {
+			out[i] = o
+		} else {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// MemoryBytes parses Memory; 0 when unset.
+func (l ResourceLimits) MemoryBytes() (int64, error) {
+	if l.Memory == "" {
+		return 0, nil
+	}
+	n, err := ParseByteSize(l.Memory)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit: %w", err)
+	}
+	return n, nil
+}
+
+// NanoCPUs parses CPUs into the Engine API's NanoCpus unit; 0 when unset.
+func (l ResourceLimits) NanoCPUs() (int64, error) {
+	if l.CPUs == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(l.CPUs, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid cpus limit %q: want a positive number", l.CPUs)
+	}
+	return int64(f * 1e9), nil
+}
+
+// MaxOutputBytes parses MaxOutput; 0 when unset (unlimited).
+func (l ResourceLimits) MaxOutputBytes() (int64, error) {
+	if l.MaxOutput == "" {
+		return 0, nil
+	}
+	n, err := ParseByteSize(l.MaxOutput)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max output: %w", err)
+	}
+	return n, nil
+}
+
+// ParseByteSize parses sizes like "512", "64k", "512m", "1g" (binary units, optional trailing "b").
+func ParseByteSize(s string) (int64, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	str = strings.TrimSuffix(str, "b")
+	mult := int64(1)
+	if str != "" {
+		switch str[len(str)-1] {
+		case 'k':
+			mult = 1 << 10
+		case 'm':
+			mult = 1 << 20
+		case 'g':
+			mult = 1 << 30
+		}
+		if mult > 1 {
+			str = str[:len(str)-1]
+		}
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive number with optional k, m or g suffix", s)
+	}
+	if n > math.MaxInt64/mult {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+	return n * mult, nil
+}
+
+// Ulimit is one parsed --ulimit value.
+type Ulimit struct {
+	Name string
+	Soft int64
+	Hard int64
+}
+
+// parseUlimit parses "name=soft[:hard]".
+func parseUlimit(s string) (Ulimit, error) {
+	name, val, ok := strings.Cut(s, "=")
+	if !ok || name == "" || val == "" {
+		return Ulimit{}, fmt.Errorf("invalid ulimit %q: want name=soft[:hard]", s)
+	}
+	softStr, hardStr, hasHard := strings.Cut(val, ":")
+	soft, err := strconv.ParseInt(softStr, 10, 64)
+	if err != nil {
+		return Ulimit{}, fmt.Errorf("invalid ulimit %q: %w", s, err)
+	}
+	hard := soft
+	if hasHard {
+		if hard, err = strconv.ParseInt(hardStr, 10, 64); err != nil {
+			return Ulimit{}, fmt.Errorf("invalid ulimit %q: %w", s, err)
+		}
+	}
+	return Ulimit{Name: name, Soft: soft, Hard: hard}, nil
+}
+
+// outputCap enforces ResourceLimits.MaxOutput across stdout and stderr combined.
+// Once the cap is reached further output is discarded (the pipes keep draining so the
+// program never blocks on a full pipe) and truncated is set.
+type outputCap struct {
+	mu        sync.Mutex
+	remaining int64 // < 0 = unlimited
+	truncated bool
+}
+
+// newOutputCap returns a cap for spec's limits; unlimited when MaxOutput is unset or invalid
+// (runners validate limits before starting).
+func newOutputCap(limits ResourceLimits) *outputCap {
+	n, err := limits.MaxOutputBytes()
+	if err != nil || n == 0 {
+		return &outputCap{remaining: -1}
+	}
+	return &outputCap{remaining: n}
+}
+
+// take returns the prefix of p that still fits, never splitting a UTF-8 sequence.
+func (c *outputCap) take(p []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.remaining < 0 {
+		return p
+	}
+	if int64(len(p)) <= c.remaining {
+		c.remaining -= int64(len(p))
+		return p
+	}
+	cut := int(c.remaining)
+	for cut > 0 && cut < len(p) && !utf8.RuneStart(p[cut]) {
+		cut--
+	}
+	c.remaining = 0
+	c.truncated = true
+	return p[:cut]
+}
+
+// Truncated reports whether any output was dropped.
+func (c *outputCap) Truncated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.truncated
+}
+
+// writer wraps w so writes beyond the cap are silently discarded.
+func (c *outputCap) writer(w io.Writer) io.Writer {
+	if w == nil || c.remaining < 0 {
+		return w
+	}
+	return cappedWriter{cap: c, w: w}
+}
+
+type cappedWriter struct {
+	cap *outputCap
+	w   io.Writer
+}
+
+func (cw cappedWriter) Write(p []byte) (int, error) {
+	if kept := cw.cap.take(p); len(kept) > 0 {
+		if _, err := cw.w.Write(kept); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"512":  512,
+		"64k":  64 << 10,
+		"512m": 512 << 20,
+		"1g":   1 << 30,
+		"2GB":  2 << 30,
+	}
+	for in, want := range cases {
+		got, err := ParseByteSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "m", "-1", "1t", "lots", "9223372036854775807g", "8589934592g"} {
+		if _, err := ParseByteSize(bad); err == nil {
+			t.Errorf("ParseByteSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestResourceLimits_Validate(t *testing.T) {
+	ok := ResourceLimits{Memory: "256m", CPUs: "0.5", PidsLimit: 64, Ulimits: []string{"nofile=1024:2048", "nproc=32"}, MaxOutput: "1m"}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for _, bad := range []ResourceLimits{
+		{Memory: "lots"},
+		{CPUs: "0"},
+		{PidsLimit: -1},
+		{Ulimits: []string{"nofile"}},
+		{Ulimits: []string{"nofile=a:b"}},
+		{MaxOutput: "x"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", bad)
+		}
+	}
+}
+
+func TestResourceLimits_MergeAndWithin(t *testing.T) {
+	base := ResourceLimits{Memory: "1g", CPUs: "2", PidsLimit: 512, MaxOutput: "1m"}
+	got := base.Merge(ResourceLimits{Memory: "256m", MaxOutput: "64k"})
+	want := ResourceLimits{Memory: "256m", CPUs: "2", PidsLimit: 512, MaxOutput: "64k"}
+	if got.Memory != want.Memory || got.CPUs != want.CPUs || got.PidsLimit != want.PidsLimit || got.MaxOutput != want.MaxOutput {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+	if err := got.Within(base); err != nil {
+		t.Errorf("lowered limits should be within base: %v", err)
+	}
+	if err := (ResourceLimits{Memory: "2g"}).Within(base); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("2g within 1g: err = %v", err)
+	}
+	if err := (ResourceLimits{CPUs: "4"}).Within(base); err == nil {
+		t.Error("4 cpus within 2 should fail")
+	}
+	if err := (ResourceLimits{Memory: "64g"}).Within(ResourceLimits{}); err != nil {
+		t.Errorf("unset ceiling should not bound: %v", err)
+	}
+	if err := (ResourceLimits{PidsLimit: -1}).Validate(); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Errorf("negative pids: err = %v", err)
+	}
+}
+
+func TestResourceLimits_Ulimits(t *testing.T) {
+	ceiling := ResourceLimits{Ulimits: []string{"nofile=1024:2048", "nproc=64"}}
+	got := ceiling.Merge(ResourceLimits{Ulimits: []string{"nofile=512", "core=0"}})
+	if want := "nofile=512 nproc=64 core=0"; strings.Join(got.Ulimits, " ") != want {
+		t.Errorf("Merge ulimits = %v, want %s", got.Ulimits, want)
+	}
+	if err := got.Within(ceiling); err != nil {
+		t.Errorf("lowered ulimits should be within the ceiling: %v", err)
+	}
+	for _, above := range []string{"nofile=4096", "nofile=1024:4096", "nproc=65"} {
+		err := (ResourceLimits{Ulimits: []string{above}}).Within(ceiling)
+		if err == nil || !strings.Contains(err.Error(), "ulimit") {
+			t.Errorf("%s within %v: err = %v", above, ceiling.Ulimits, err)
+		}
+	}
+}
+
+func TestDockerRunBuilder_Limits(t *testing.T) {
+	got := newDockerRunBuilder("linux/amd64", false).
+		Limits(ResourceLimits{Memory: "256m", CPUs: "0.5", PidsLimit: 64, Ulimits: []string{"nofile=1024"}, MaxOutput: "1k"}).
+		Image("img").Build()
+	joined := strings.Join(got, " ")
+	for _, want := range []string{"--memory 256m", "--cpus 0.5", "--pids-limit 64", "--ulimit nofile=1024"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in %v", want, got)
+		}
+	}
+	if strings.Contains(joined, "1k") {
+		t.Errorf("max output is enforced by the runner, not docker: %v", got)
+	}
+	if slices.Index(got, "--memory") > slices.Index(got, "img") {
+		t.Errorf("limits must precede the image: %v", got)
+	}
+}
+
+func TestOutputCap_Take(t *testing.T) {
+	c := newOutputCap(ResourceLimits{MaxOutput: "5"})
+	if got := string(c.take([]byte("abc"))); got != "abc" {
+		t.Errorf("first take = %q", got)
+	}
+	// "dé" is 3 bytes; only 2 remain, so the cut backs off to the rune start.
+	if got := string(c.take([]byte("dé"))); got != "d" {
+		t.Errorf("second take = %q, want %q", got, "d")
+	}
+	if got := c.take([]byte("more")); len(got) != 0 || !c.Truncated() {
+		t.Errorf("after cap: take = %q, truncated = %v", got, c.Truncated())
+	}
+
+	unlimited := newOutputCap(ResourceLimits{})
+	if got := string(unlimited.take([]byte("anything"))); got != "anything" || unlimited.Truncated() {
+		t.Errorf("unlimited cap altered output: %q", got)
+	}
+}
+
+func TestLocalRunner_Run_MaxOutput(t *testing.T) {
+	r := &localRunner{}
+	// Produce far more than the cap; the run must still complete with a clean prefix.
+	result, err := r.Run(context.Background(), RunSpec{
+		Command: "yes fraglet | head -c 1000000",
+		Limits:  ResourceLimits{MaxOutput: "1k"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Stdout) != 1024 || !strings.HasPrefix(result.Stdout, "fraglet\n") {
+		t.Errorf("stdout len = %d, prefix %q", len(result.Stdout), result.Stdout[:min(16, len(result.Stdout))])
+	}
+	if !result.Truncated {
+		t.Error("expected Truncated")
+	}
+
+	var sink strings.Builder
+	result, err = r.Run(context.Background(), RunSpec{
+		Command: "yes fraglet | head -c 100000",
+		Stdout:  &sink,
+		Limits:  ResourceLimits{MaxOutput: "64"},
+	})
+	if err != nil {
+		t.Fatalf("Run with writer: %v", err)
+	}
+	if sink.Len() != 64 || !result.Truncated {
+		t.Errorf("writer got %d bytes, truncated = %v", sink.Len(), result.Truncated)
+	}
+}
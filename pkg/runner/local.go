@@ -1,10 +1,8 @@
 package runner
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 )
@@ -23,11 +21,12 @@ func (r *localRunner) Available() bool {
 
 func (r *localRunner) Run(ctx context.Context, spec RunSpec) (RunResult, error) {
 	// Use RunStreaming and collect results
+	spec, limit := withOutputCap(spec)
 	streaming, err := r.RunStreaming(ctx, spec)
 	if err != nil {
-		return RunResult{}, err
+		return infraResult(), err
 	}
-	return collectStreamingResults(ctx, streaming)
+	return collectStreamingResults(ctx, streaming, limit, spec.stopGrace())
 }
 
 func (r *localRunner) RunStreaming(ctx context.Context, spec RunSpec) (*StreamingResult, error) {
@@ -59,123 +58,18 @@ func (r *localRunner) RunStreaming(ctx context.Context, spec RunSpec) (*Streamin
 		return nil, fmt.Errorf("no command, entrypoint, or volumes specified")
 	}
 
-	stdoutChan := make(chan string, 10)
-	stderrChan := make(chan string, 10)
-	doneChan := make(chan error, 1)
-	exitCodeChan := make(chan int, 1)
-
-	if spec.StdinReader != nil {
-		cmd.Stdin = spec.StdinReader
-	} else if spec.Stdin != "" {
-		cmd.Stdin = bytes.NewBufferString(spec.Stdin)
-	}
-
-	if len(spec.Env) > 0 {
-		// Extend environment - start with current env and add spec.Env
-		cmd.Env = append(os.Environ(), spec.Env...)
+	if env := spec.env(); len(env) > 0 {
+		// Extend environment - start with current env and add spec.Env and spec.SecretEnv
+		cmd.Env = append(os.Environ(), env...)
 	}
 
 	if spec.WorkDir != "" {
 		cmd.Dir = spec.WorkDir
 	}
 
-	if spec.Stdout != nil {
-		cmd.Stdout = spec.Stdout
-	}
-	if spec.Stderr != nil {
-		cmd.Stderr = spec.Stderr
-	}
-
-	var stdoutPipe, stderrPipe io.ReadCloser
-	if spec.Stdout == nil {
-		var err error
-		stdoutPipe, err = cmd.StdoutPipe()
-		if err != nil {
-			if cleanup != nil {
-				cleanup()
-			}
-			return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
-		}
-	}
-	if spec.Stderr == nil {
-		var err error
-		stderrPipe, err = cmd.StderrPipe()
-		if err != nil {
-			if cleanup != nil {
-				cleanup()
-			}
-			return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-		}
-	}
-
-	if err := cmd.Start(); err != nil {
-		if cleanup != nil {
-			cleanup()
-		}
-		return nil, fmt.Errorf("failed to start command: %w", err)
-	}
-
-	if spec.Stdout == nil {
-		go func() {
-			defer close(stdoutChan)
-			buf := make([]byte, 4096)
-			for {
-				n, err := stdoutPipe.Read(buf)
-				if n > 0 {
-					stdoutChan <- string(buf[:n])
-				}
-				if err != nil {
-					break
-				}
-			}
-		}()
-	}
-	if spec.Stderr == nil {
-		go func() {
-			defer close(stderrChan)
-			buf := make([]byte, 4096)
-			for {
-				n, err := stderrPipe.Read(buf)
-				if n > 0 {
-					stderrChan <- string(buf[:n])
-				}
-				if err != nil {
-					break
-				}
-			}
-		}()
-	}
-
-	go func() {
-		err := cmd.Wait()
-		if cleanup != nil {
-			cleanup()
-		}
-		if err != nil {
-			if exitErr, ok := err.(*exec.ExitError); ok {
-				exitCodeChan <- exitErr.ExitCode()
-			} else {
-				exitCodeChan <- -1
-			}
-			doneChan <- err
-		} else {
-			exitCodeChan <- 0
-			doneChan <- nil
-		}
-		if spec.Stdout != nil {
-			close(stdoutChan)
-		}
-		if spec.Stderr != nil {
-			close(stderrChan)
-		}
-		close(exitCodeChan)
-		close(doneChan)
-	}()
+	// CommandContext kills the process on cancellation; WaitDelay stops Wait blocking on
+	// output still held open by its children.
+	cmd.WaitDelay = spec.stopGrace()
 
-	return &StreamingResult{
-		Stdout:   stdoutChan,
-		Stderr:   stderrChan,
-		Done:     doneChan,
-		ExitCode: exitCodeChan,
-	}, nil
+	return startProcess(cmd, spec, processHooks{cleanup: cleanup})
 }
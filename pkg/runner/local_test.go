@@ -1,10 +1,35 @@
 package runner
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 )
 
+// syncBuffer is a bytes.Buffer safe to read while a run writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestLocalRunner_Run_WithShebang(t *testing.T) {
 	r := &localRunner{}
 
@@ -71,3 +96,87 @@ func TestLocalRunner_Run_NoShebang(t *testing.T) {
 		t.Errorf("Expected 'hello world\\n', got %q", result.Stdout)
 	}
 }
+
+func TestLocalRunner_Run_Timeout(t *testing.T) {
+	r := &localRunner{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result, err := r.Run(ctx, RunSpec{Command: "echo before; sleep 10"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if result.Termination != TerminationTimeout {
+		t.Errorf("termination = %q, want %q", result.Termination, TerminationTimeout)
+	}
+	if result.Stdout != "before\n" {
+		t.Errorf("partial output lost: %q", result.Stdout)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run outlived its timeout by %s", elapsed)
+	}
+}
+
+func TestLocalRunner_Run_Termination(t *testing.T) {
+	r := &localRunner{}
+	tests := []struct {
+		command string
+		want    TerminationReason
+	}{
+		{"exit 3", TerminationExited},
+		{"kill -9 $$", TerminationSignaled},
+	}
+	for _, tt := range tests {
+		result, _ := r.Run(context.Background(), RunSpec{Command: tt.command})
+		if result.Termination != tt.want {
+			t.Errorf("%q: termination = %q, want %q", tt.command, result.Termination, tt.want)
+		}
+		if result.Timings.Execute <= 0 {
+			t.Errorf("%q: execute phase not timed: %+v", tt.command, result.Timings)
+		}
+	}
+}
+
+func TestLocalRunner_Run_ForwardsSignals(t *testing.T) {
+	r := &localRunner{}
+	signals := make(chan os.Signal, 1)
+	stdout := &syncBuffer{}
+	go func() {
+		for !strings.Contains(stdout.String(), "ready") {
+			time.Sleep(10 * time.Millisecond)
+		}
+		signals <- syscall.SIGINT
+	}()
+
+	result, err := r.Run(context.Background(), RunSpec{
+		Command: `trap 'echo handled; exit 3' INT; echo ready; while :; do sleep 0.05; done`,
+		Stdout:  stdout,
+		Signals: signals,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 3 || !strings.Contains(stdout.String(), "handled") {
+		t.Errorf("exit = %d, stdout = %q; want the trap to run", result.ExitCode, stdout.String())
+	}
+}
+
+func TestLocalRunner_Run_KillsAfterSignalGrace(t *testing.T) {
+	r := &localRunner{}
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+
+	start := time.Now()
+	result, _ := r.Run(context.Background(), RunSpec{
+		Command:   `trap '' TERM; while :; do sleep 0.05; done`,
+		Signals:   signals,
+		StopGrace: 200 * time.Millisecond,
+	})
+	if result.Termination != TerminationSignaled {
+		t.Errorf("termination = %q, want signaled", result.Termination)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ignored signal was not escalated (%s)", elapsed)
+	}
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"context"
+	"strings"
+)
+
+// podmanRunner executes commands inside Podman containers (rootless or rootful).
+// Podman accepts the same run flags as docker, so argv construction is shared.
+type podmanRunner struct{}
+
+func (r *podmanRunner) Name() string {
+	return "podman"
+}
+
+func (r *podmanRunner) Available() bool {
+	return cliAvailable("podman")
+}
+
+// Probe reports why podman is unusable (missing binary, unreachable service).
+func (r *podmanRunner) Probe() error {
+	return cliProbe("podman")
+}
+
+func (r *podmanRunner) Run(ctx context.Context, spec RunSpec) (RunResult, error) {
+	// Use RunStreaming and collect results
+	spec, limit := withOutputCap(spec)
+	streaming, err := r.RunStreaming(ctx, spec)
+	if err != nil {
+		return infraResult(), err
+	}
+	return collectStreamingResults(ctx, streaming, limit, spec.stopGrace())
+}
+
+func (r *podmanRunner) RunStreaming(ctx context.Context, spec RunSpec) (*StreamingResult, error) {
+	return runCLIStreaming(ctx, "podman", qualifyPodmanImage(spec.Container), spec)
+}
+
+// qualifyPodmanImage prefixes docker.io/ on "namespace/repo" references without a registry host.
+// Podman refuses to pull unqualified short names unless unqualified-search-registries is configured,
+// and veins.yml uses Docker Hub short names (e.g. 100hellos/python:latest).
+// Single-component names (e.g. fraglet-test:local) are left alone so local images still resolve.
+func qualifyPodmanImage(image string) string {
+	first, _, hasSlash := strings.Cut(image, "/")
+	if !hasSlash {
+		return image
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return image
+	}
+	return "docker.io/" + image
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePodman installs a "podman" script on PATH that records each invocation's argv
+// (one arg per line, invocations separated by "--") and echoes stdin for "run".
+// With FAKE_PODMAN_BLOCK set, "run" blocks until a "stop" invocation kills it.
+// Returns the path of the argv log.
+func fakePodman(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "argv.log")
+	pidPath := filepath.Join(dir, "run.pid")
+	script := `#!/bin/sh
+for a in "$@"; do printf '%s\n' "$a" >> "` + logPath + `"; done
+echo -- >> "` + logPath + `"
+if [ "$1" = "run" ] && [ -n "$FAKE_PODMAN_BLOCK" ]; then echo $$ > "` + pidPath + `"; exec sleep 30; fi
+if [ "$1" = "run" ]; then cat; fi
+if [ "$1" = "stop" ] && [ -f "` + pidPath + `" ]; then kill "$(cat "` + pidPath + `")"; fi
+exit 0
+`
+	if err := os.WriteFile(filepath.Join(dir, "podman"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+// invocations splits the fake CLI's argv log into one []string per call.
+func invocations(t *testing.T, logPath string) [][]string {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls [][]string
+	var cur []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if line == "--" {
+			calls = append(calls, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, line)
+	}
+	return calls
+}
+
+func TestPodmanRunner_MapsRunSpec(t *testing.T) {
+	logPath := fakePodman(t)
+	r := &podmanRunner{}
+	if !r.Available() {
+		t.Fatal("fake podman should be available")
+	}
+
+	spec := RunSpec{
+		Container:   "100hellos/python:latest",
+		Platform:    "linux/arm64",
+		Env:         []string{"FRAGLET_MODE=main", "CITY=paris"},
+		NetworkMode: "none",
+		Stdin:       "piped input",
+		Args:        []string{"a1", "a2"},
+		Volumes: []VolumeMount{
+			{HostPath: "/tmp/code", ContainerPath: "/FRAGLET"},
+			{HostPath: "/tmp/out", ContainerPath: "/out", Writable: true},
+		},
+	}
+	result, err := r.Run(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stdout != "piped input" {
+		t.Errorf("stdin not forwarded: stdout = %q", result.Stdout)
+	}
+
+	calls := invocations(t, logPath)
+	var run []string
+	for _, c := range calls {
+		if len(c) > 0 && c[0] == "run" {
+			run = c
+		}
+	}
+	if run == nil {
+		t.Fatalf("no podman run invocation recorded: %v", calls)
+	}
+	joined := strings.Join(run, " ")
+	for _, want := range []string{
+		"run --rm -i",
+		"--platform linux/arm64",
+		"--cap-drop=all",
+		"--security-opt=no-new-privileges",
+		"--network none",
+		"-e FRAGLET_MODE=main",
+		"-e CITY=paris",
+		"-v /tmp/code:/FRAGLET:ro",
+		"-v /tmp/out:/out",
+		"docker.io/100hellos/python:latest a1 a2",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("podman argv missing %q: %v", want, run)
+		}
+	}
+	if slices.Contains(run, "/tmp/out:/out:ro") {
+		t.Errorf("writable volume must not be read-only: %v", run)
+	}
+}
+
+func TestQualifyPodmanImage(t *testing.T) {
+	tests := map[string]string{
+		"100hellos/python:latest":      "docker.io/100hellos/python:latest",
+		"ghcr.io/foo/bar:1":            "ghcr.io/foo/bar:1",
+		"localhost/fraglet-test:local": "localhost/fraglet-test:local",
+		"registry:5000/x/y":            "registry:5000/x/y",
+		"fraglet-test:local":           "fraglet-test:local",
+	}
+	for in, want := range tests {
+		if got := qualifyPodmanImage(in); got != want {
+			t.Errorf("qualifyPodmanImage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestForName(t *testing.T) {
+	for _, name := range []string{"docker", "podman", "local"} {
+		r, err := ForName(name)
+		if err != nil {
+			t.Fatalf("ForName(%q): %v", name, err)
+		}
+		if r.Name() != name {
+			t.Errorf("ForName(%q).Name() = %q", name, r.Name())
+		}
+	}
+	if _, err := ForName("lxc"); err == nil {
+		t.Error("expected error for unknown runner")
+	}
+}
+
+func TestPodmanRunner_TimeoutStopsContainer(t *testing.T) {
+	logPath := fakePodman(t)
+	t.Setenv("FAKE_PODMAN_BLOCK", "1")
+	r := &podmanRunner{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result, err := r.Run(ctx, RunSpec{Container: "img", Command: "sleep 30"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if result.Termination != TerminationTimeout {
+		t.Errorf("termination = %q, want %q", result.Termination, TerminationTimeout)
+	}
+
+	var name string
+	var stopped, removed bool
+	for _, c := range invocations(t, logPath) {
+		switch c[0] {
+		case "run":
+			if i := slices.Index(c, "--name"); i >= 0 {
+				name = c[i+1]
+			}
+		case "stop":
+			stopped = name != "" && slices.Equal(c, []string{"stop", "-t", "2", name})
+		case "rm":
+			removed = name != "" && slices.Equal(c, []string{"rm", "-f", name})
+		}
+	}
+	if !strings.HasPrefix(name, "fraglet-") {
+		t.Fatalf("run was not given a unique name: %q", name)
+	}
+	if !stopped || !removed {
+		t.Errorf("container %s not stopped (%v) and removed (%v): %v", name, stopped, removed, invocations(t, logPath))
+	}
+}
@@ -0,0 +1,483 @@
+package runner
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+)
+
+// keepAliveCmd is the process a warm container idles in until a run execs into it.
+var keepAliveCmd = []string{"tail", "-f", "/dev/null"}
+
+// PoolOptions configures a warm container pool.
+type PoolOptions struct {
+	Size     int           // idle containers kept ready per image/configuration; 0 disables pooling
+	IdleTTL  time.Duration // idle containers unused for this long are removed; 0 = keep until Close
+	MaxTotal int           // cap on pooled containers (idle, starting and running) across all images; 0 = no cap
+	OnError  func(error)   // called with warm-up and discarded-container failures, which no run sees; nil = ignore
+}
+
+// Pool is a docker Runner that keeps pre-started containers per image and runs fraglets in them
+// with exec, skipping container startup. Each container serves exactly one run and is then
+// removed; a replacement is started in the background. Specs the pool cannot serve (commands,
+// directory or writable mounts) and runs that find no idle container use a regular cold run.
+//
+// Containers are keyed by everything fixed at creation (image, platform, network, limits), so
+// modes and params, which travel as exec environment, share a pool.
+type Pool struct {
+	client *dockerapi.Client
+	cold   Runner
+	opts   PoolOptions
+
+	mu      sync.Mutex
+	idle    map[poolKey][]*warmContainer
+	warming map[poolKey]int
+	total   int
+	closed  bool
+
+	wg         sync.WaitGroup // warm-ups and post-run removals
+	stopReaper chan struct{}
+}
+
+type poolKey struct {
+	image, platform, network, limits string
+}
+
+type warmContainer struct {
+	id        string
+	image     dockerapi.ImageConfig
+	idleSince time.Time
+}
+
+// NewPool wraps base, which must be the docker runner with a reachable Engine API.
+func NewPool(base Runner, opts PoolOptions) (*Pool, error) {
+	d, ok := base.(*dockerRunner)
+	if !ok {
+		return nil, fmt.Errorf("warm pool requires the docker runner, not %s", base.Name())
+	}
+	c := d.api(context.Background())
+	if c == nil {
+		return nil, fmt.Errorf("warm pool requires the Docker Engine API")
+	}
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("warm pool size must be positive")
+	}
+	p := &Pool{
+		client:     c,
+		cold:       &dockerRunner{client: c},
+		opts:       opts,
+		idle:       map[poolKey][]*warmContainer{},
+		warming:    map[poolKey]int{},
+		stopReaper: make(chan struct{}),
+	}
+	if opts.IdleTTL > 0 {
+		go p.reap()
+	}
+	return p, nil
+}
+
+func (p *Pool) Name() string {
+	return "docker"
+}
+
+func (p *Pool) Available() bool {
+	return true
+}
+
+func (p *Pool) Run(ctx context.Context, spec RunSpec) (RunResult, error) {
+	spec, limit := withOutputCap(spec)
+	streaming, err := p.RunStreaming(ctx, spec)
+	if err != nil {
+		return infraResult(), err
+	}
+	return collectStreamingResults(ctx, streaming, limit, spec.stopGrace())
+}
+
+func (p *Pool) RunStreaming(ctx context.Context, spec RunSpec) (*StreamingResult, error) {
+	if !poolable(spec) {
+		return p.cold.RunStreaming(ctx, spec)
+	}
+	if err := spec.Limits.Validate(); err != nil {
+		return nil, err
+	}
+	key := keyFor(spec)
+	w := p.take(key)
+	p.fill(key, spec)
+	if w == nil {
+		return p.cold.RunStreaming(ctx, spec)
+	}
+	return p.runWarm(ctx, w, spec)
+}
+
+// Idle returns the number of idle containers ready for spec.
+func (p *Pool) Idle(spec RunSpec) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle[keyFor(spec)])
+}
+
+// Close stops replenishing, waits for in-flight warm-ups and removals, and removes every idle
+// container. Runs started before Close finish normally.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.stopReaper)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	var ids []string
+	for key, ws := range p.idle {
+		for _, w := range ws {
+			ids = append(ids, w.id)
+		}
+		delete(p.idle, key)
+	}
+	p.total -= len(ids)
+	p.mu.Unlock()
+	for _, id := range ids {
+		p.remove(id)
+	}
+	return nil
+}
+
+// poolable reports whether spec is a plain fraglet run whose inputs can be copied in:
+// image entrypoint, read-only regular-file mounts only. Runs that forward signals or use a TTY
+// need the program to be the container's main process, so they run cold.
+func poolable(spec RunSpec) bool {
+	if spec.Container == "" || spec.Command != "" || spec.Entrypoint != "" || spec.Signals != nil || spec.TTY {
+		return false
+	}
+	for _, v := range spec.Volumes {
+		if !copyable(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func keyFor(spec RunSpec) poolKey {
+	platform := spec.Platform
+	if platform == "" {
+		platform = "linux/amd64"
+	}
+	limits := spec.Limits
+	limits.MaxOutput = "" // enforced host-side, not a container property
+	return poolKey{
+		image:    spec.Container,
+		platform: platform,
+		network:  spec.NetworkMode,
+		limits:   fmt.Sprintf("%v", limits),
+	}
+}
+
+// take removes and returns an idle container for key, or nil.
+func (p *Pool) take(key poolKey) *warmContainer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ws := p.idle[key]
+	if len(ws) == 0 {
+		return nil
+	}
+	w := ws[0]
+	p.idle[key] = ws[1:]
+	return w
+}
+
+// fill starts background warm-ups until key has Size idle or starting containers, within MaxTotal.
+func (p *Pool) fill(key poolKey, spec RunSpec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for !p.closed && len(p.idle[key])+p.warming[key] < p.opts.Size &&
+		(p.opts.MaxTotal <= 0 || p.total < p.opts.MaxTotal) {
+		p.warming[key]++
+		p.total++
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			w, err := p.warm(spec)
+			p.mu.Lock()
+			p.warming[key]--
+			if err != nil || p.closed {
+				p.total--
+				p.mu.Unlock()
+				if w != nil {
+					p.remove(w.id)
+				}
+				if err != nil && p.opts.OnError != nil {
+					p.opts.OnError(fmt.Errorf("warm pool: %w", err))
+				}
+				return
+			}
+			w.idleSince = time.Now()
+			p.idle[key] = append(p.idle[key], w)
+			p.mu.Unlock()
+		}()
+	}
+}
+
+// warm creates and starts one idle container for spec's image and configuration.
+func (p *Pool) warm(spec RunSpec) (*warmContainer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	key := keyFor(spec)
+	if err := ensureImageAPI(ctx, p.client, spec.Container, key.platform); err != nil {
+		return nil, err
+	}
+	img, err := p.client.ImageInspect(ctx, spec.Container)
+	if err != nil {
+		return nil, fmt.Errorf("inspect %s: %w", spec.Container, err)
+	}
+	if img.Config == nil || len(img.Config.Entrypoint)+len(img.Config.Cmd) == 0 {
+		return nil, fmt.Errorf("image %s has no entrypoint to exec", spec.Container)
+	}
+
+	cfg, _, cleanup, err := apiContainerConfig(RunSpec{
+		Container:   spec.Container,
+		NetworkMode: spec.NetworkMode,
+		Limits:      spec.Limits,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	cleanup()
+	cfg.Entrypoint = keepAliveCmd
+	cfg.Cmd = nil
+	cfg.AttachStdout, cfg.AttachStderr = false, false
+
+	id, err := p.client.ContainerCreate(ctx, newContainerName(), key.platform, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create warm container: %w", err)
+	}
+	w := &warmContainer{id: id, image: *img.Config}
+	if err := p.client.ContainerStart(ctx, id); err != nil {
+		return w, fmt.Errorf("start warm container: %w", err)
+	}
+	return w, nil
+}
+
+// runWarm copies spec's files into w and execs the image entrypoint there. The container is
+// removed once the run ends, however it ends. If w cannot take the run (it died or was removed
+// while idle), it is discarded and spec runs in a cold container instead.
+func (p *Pool) runWarm(ctx context.Context, w *warmContainer, spec RunSpec) (*StreamingResult, error) {
+	fail := func(err error) (*StreamingResult, error) {
+		p.release(w.id)
+		if p.opts.OnError != nil {
+			p.opts.OnError(fmt.Errorf("warm pool: discarded container: %w", err))
+		}
+		return p.cold.RunStreaming(ctx, spec)
+	}
+
+	phase := time.Now()
+	archive, err := volumesArchive(spec.Volumes)
+	if err != nil {
+		return fail(err)
+	}
+	if err := p.client.CopyToContainer(ctx, w.id, "/", archive); err != nil {
+		return fail(fmt.Errorf("copy fraglet into warm container: %w", err))
+	}
+
+	cmd := append([]string{}, w.image.Entrypoint...)
+	if len(spec.Args) > 0 {
+		cmd = append(cmd, spec.Args...)
+	} else {
+		cmd = append(cmd, w.image.Cmd...)
+	}
+	workDir := spec.WorkDir
+	if workDir == "" {
+		workDir = w.image.WorkingDir
+	}
+	attachStdin := spec.StdinReader != nil || spec.Stdin != ""
+	execID, err := p.client.ExecCreate(ctx, w.id, dockerapi.ExecConfig{
+		Cmd:          cmd,
+		Env:          spec.env(),
+		WorkingDir:   workDir,
+		AttachStdin:  attachStdin,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("exec in warm container: %w", err))
+	}
+	stream, err := p.client.ExecStart(ctx, execID)
+	if err != nil {
+		return fail(fmt.Errorf("start exec in warm container: %w", err))
+	}
+
+	stdoutChan := make(chan string, 10)
+	stderrChan := make(chan string, 10)
+	doneChan := make(chan error, 1)
+	exitCodeChan := make(chan int, 1)
+	infoChan := make(chan RunInfo, 1)
+	timings := PhaseTimings{Create: time.Since(phase)}
+	phase = time.Now()
+
+	var stdout, stderr io.Writer = chanWriter(stdoutChan), chanWriter(stderrChan)
+	if spec.Stdout != nil {
+		stdout = spec.Stdout
+	}
+	if spec.Stderr != nil {
+		stderr = spec.Stderr
+	}
+
+	if attachStdin {
+		stdin := spec.StdinReader
+		if stdin == nil {
+			stdin = bytes.NewBufferString(spec.Stdin)
+		}
+		go func() {
+			_, _ = io.Copy(stream, stdin)
+			_ = stream.CloseWrite()
+		}()
+	}
+
+	// Cancellation stops the whole container, which ends the exec stream.
+	outputDone := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			stopAPIContainer(p.client, w.id, spec.stopGrace())
+		case <-outputDone:
+		}
+	}()
+
+	go func() {
+		_ = dockerapi.Demux(stream, stdout, stderr)
+		close(outputDone)
+		stream.Close()
+		timings.Execute = time.Since(phase)
+		code := -1
+		termination := TerminationReason("")
+		inspectCtx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+		if info, err := p.client.ExecInspect(inspectCtx, execID); err == nil && !info.Running {
+			code = info.ExitCode
+		}
+		if info, err := p.client.ContainerInspect(inspectCtx, w.id); err == nil && info.State.OOMKilled {
+			termination = TerminationOOMKilled
+		}
+		cancel()
+		if termination == "" {
+			termination = terminationForExit(code)
+		}
+		p.release(w.id)
+		close(stdoutChan)
+		close(stderrChan)
+		exitCodeChan <- code
+		infoChan <- RunInfo{Timings: timings, Termination: termination}
+		doneChan <- ctx.Err()
+		close(exitCodeChan)
+		close(infoChan)
+		close(doneChan)
+	}()
+
+	return &StreamingResult{
+		Stdout:   stdoutChan,
+		Stderr:   stderrChan,
+		Done:     doneChan,
+		ExitCode: exitCodeChan,
+		Info:     infoChan,
+	}, nil
+}
+
+// release removes a used container in the background and frees its slot. Once the pool is
+// closed, Close may already be past wg.Wait, so the container is removed before release returns.
+func (p *Pool) release(id string) {
+	p.mu.Lock()
+	if p.closed {
+		p.total--
+		p.mu.Unlock()
+		p.remove(id)
+		return
+	}
+	p.wg.Add(1)
+	p.mu.Unlock()
+	go func() {
+		defer p.wg.Done()
+		p.remove(id)
+		p.mu.Lock()
+		p.total--
+		p.mu.Unlock()
+	}()
+}
+
+func (p *Pool) remove(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+	defer cancel()
+	_ = p.client.ContainerRemove(ctx, id, true)
+}
+
+// reap removes containers that have been idle longer than IdleTTL.
+func (p *Pool) reap() {
+	interval := p.opts.IdleTTL / 2
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopReaper:
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			var expired []string
+			for key, ws := range p.idle {
+				kept := ws[:0]
+				for _, w := range ws {
+					if now.Sub(w.idleSince) >= p.opts.IdleTTL {
+						expired = append(expired, w.id)
+					} else {
+						kept = append(kept, w)
+					}
+				}
+				p.idle[key] = kept
+			}
+			p.mu.Unlock()
+			for _, id := range expired {
+				p.release(id)
+			}
+		}
+	}
+}
+
+// volumesArchive packs each mounted file at its container path for CopyToContainer(..., "/", ...).
+func volumesArchive(volumes []VolumeMount) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, v := range volumes {
+		data, err := os.ReadFile(v.HostPath)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", v.HostPath, err)
+		}
+		hdr := &tar.Header{
+			Name:    strings.TrimPrefix(v.ContainerPath, "/"),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
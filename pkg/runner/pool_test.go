@@ -0,0 +1,313 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+	"github.com/ofthemachine/fraglet/pkg/dockerapi/dockerapitest"
+)
+
+// newPoolServer returns a fake daemon whose containers idle in the keep-alive command until
+// stopped and whose execs print the mounted fraglet, the exec command and FRAGLET_MODE.
+func newPoolServer(t *testing.T) *dockerapitest.Server {
+	t.Helper()
+	srv := dockerapitest.NewServer(t)
+	srv.Images["img"] = dockerapi.ImageInfo{
+		ID:     "sha256:img",
+		Config: &dockerapi.ImageConfig{Entrypoint: []string{"/fraglet-entrypoint"}, WorkingDir: "/work"},
+	}
+	srv.Program = func(c *dockerapitest.Container, stdin io.Reader, stdout, stderr io.Writer) int {
+		if slices.Equal(c.Config.Entrypoint, keepAliveCmd) {
+			<-c.Stopping()
+			return 137
+		}
+		fmt.Fprint(stdout, "cold")
+		return 0
+	}
+	srv.Exec = func(c *dockerapitest.Container, e *dockerapitest.Exec, stdin io.Reader, stdout, stderr io.Writer) int {
+		code, _ := c.File("/FRAGLET")
+		mode := ""
+		for _, kv := range e.Config.Env {
+			if v, ok := strings.CutPrefix(kv, "FRAGLET_MODE="); ok {
+				mode = v
+			}
+		}
+		fmt.Fprintf(stdout, "%s|%s|%s|%s", code, strings.Join(e.Config.Cmd, " "), e.Config.WorkingDir, mode)
+		return 4
+	}
+	return srv
+}
+
+func newTestPool(t *testing.T, srv *dockerapitest.Server, opts PoolOptions) *Pool {
+	t.Helper()
+	p, err := NewPool(&dockerRunner{client: srv.Client(t)}, opts)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func poolSpec(t *testing.T, code, mode string) RunSpec {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "code")
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return RunSpec{
+		Container: "img",
+		Env:       []string{"FRAGLET_MODE=" + mode},
+		Volumes:   []VolumeMount{{HostPath: path, ContainerPath: "/FRAGLET"}},
+	}
+}
+
+func waitIdle(t *testing.T, p *Pool, spec RunSpec, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Idle(spec) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle = %d, want %d", p.Idle(spec), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitContainers(t *testing.T, srv *dockerapitest.Server, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(srv.ContainerList()) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("containers = %d, want %d", len(srv.ContainerList()), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPool_ColdThenWarm(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 1})
+
+	first := poolSpec(t, "print(1)", "main")
+	result, err := p.Run(context.Background(), first)
+	if err != nil {
+		t.Fatalf("cold run: %v", err)
+	}
+	if result.Stdout != "cold" {
+		t.Errorf("first run stdout = %q, want a cold run", result.Stdout)
+	}
+	waitIdle(t, p, first, 1)
+
+	// A different mode shares the warm container: only the exec environment differs.
+	second := poolSpec(t, "print(2)", "other")
+	second.Args = []string{"a1"}
+	result, err = p.Run(context.Background(), second)
+	if err != nil {
+		t.Fatalf("warm run: %v", err)
+	}
+	if want := "print(2)|/fraglet-entrypoint a1|/work|other"; result.Stdout != want {
+		t.Errorf("warm stdout = %q, want %q", result.Stdout, want)
+	}
+	if result.ExitCode != 4 {
+		t.Errorf("exit code = %d, want 4", result.ExitCode)
+	}
+}
+
+func TestPool_ContainerServesOneRun(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 1})
+	spec := poolSpec(t, "x", "main")
+
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 1)
+	warm := srv.ContainerList()[0].ID
+
+	if _, err := p.Run(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	// The used container is removed and a fresh one replaces it.
+	waitIdle(t, p, spec, 1)
+	waitContainers(t, srv, 1)
+	if id := srv.ContainerList()[0].ID; id == warm {
+		t.Errorf("container %s was reused", id)
+	}
+}
+
+func TestPool_DeadWarmContainerFallsBackToCold(t *testing.T) {
+	srv := newPoolServer(t)
+	errs := make(chan error, 4)
+	p := newTestPool(t, srv, PoolOptions{Size: 1, OnError: func(err error) { errs <- err }})
+	spec := poolSpec(t, "x", "main")
+
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 1)
+	// The idle container disappears behind the pool's back.
+	warm := srv.ContainerList()[0].ID
+	if err := srv.Client(t).ContainerRemove(context.Background(), warm, true); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := p.Run(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.Stdout != "cold" || result.ExitCode != 0 {
+		t.Errorf("result = %q (exit %d), want a cold run", result.Stdout, result.ExitCode)
+	}
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "discarded") {
+			t.Errorf("OnError(%v)", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError not called for the discarded container")
+	}
+}
+
+func TestPool_MaxTotal(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 3, MaxTotal: 2})
+	spec := poolSpec(t, "x", "main")
+
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 2)
+	time.Sleep(100 * time.Millisecond)
+	if n := len(srv.ContainerList()); n != 2 {
+		t.Errorf("containers = %d, want MaxTotal 2", n)
+	}
+}
+
+func TestPool_UnpoolableSpecRunsCold(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 1})
+
+	spec := RunSpec{Container: "img", Command: "echo hi"}
+	result, err := p.Run(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stdout != "cold" {
+		t.Errorf("stdout = %q, want cold run", result.Stdout)
+	}
+	if p.Idle(spec) != 0 {
+		t.Error("command runs must not warm containers")
+	}
+}
+
+func TestPool_IdleTTLReapsContainers(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 1, IdleTTL: 200 * time.Millisecond})
+	spec := poolSpec(t, "x", "main")
+
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 1)
+	waitIdle(t, p, spec, 0)
+	waitContainers(t, srv, 0)
+}
+
+func TestPool_CloseRemovesIdle(t *testing.T) {
+	srv := newPoolServer(t)
+	p := newTestPool(t, srv, PoolOptions{Size: 2})
+	spec := poolSpec(t, "x", "main")
+
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 2)
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.ContainerList()); n != 0 {
+		t.Errorf("%d containers left after Close", n)
+	}
+	// Closed pools still run, without warming.
+	if _, err := p.Run(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	if p.Idle(spec) != 0 {
+		t.Error("closed pool warmed a container")
+	}
+}
+
+func TestPool_RunEndingAfterCloseRemovesContainer(t *testing.T) {
+	srv := newPoolServer(t)
+	finish := make(chan struct{})
+	srv.Exec = func(c *dockerapitest.Container, e *dockerapitest.Exec, stdin io.Reader, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, "started")
+		<-finish
+		return 0
+	}
+	p := newTestPool(t, srv, PoolOptions{Size: 1})
+	spec := poolSpec(t, "x", "main")
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(context.Background(), spec)
+	}()
+	// Close while the warm run is in flight; its release comes after Close's wait.
+	time.Sleep(100 * time.Millisecond)
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(finish)
+	<-done
+	if n := len(srv.ContainerList()); n != 0 {
+		t.Errorf("%d containers left after the run ended", n)
+	}
+}
+
+func TestPool_OnError(t *testing.T) {
+	srv := newPoolServer(t)
+	srv.Images["bare"] = dockerapi.ImageInfo{ID: "sha256:bare", Config: &dockerapi.ImageConfig{}}
+	errs := make(chan error, 1)
+	p := newTestPool(t, srv, PoolOptions{Size: 1, OnError: func(err error) { errs <- err }})
+	spec := poolSpec(t, "x", "main")
+	spec.Container = "bare"
+
+	p.Run(context.Background(), spec)
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "no entrypoint") {
+			t.Errorf("OnError(%v)", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError not called for a failed warm-up")
+	}
+}
+
+func TestPool_CancelStopsWarmContainer(t *testing.T) {
+	srv := newPoolServer(t)
+	srv.Exec = func(c *dockerapitest.Container, e *dockerapitest.Exec, stdin io.Reader, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, "started")
+		<-c.Stopping()
+		return 143
+	}
+	p := newTestPool(t, srv, PoolOptions{Size: 1})
+	spec := poolSpec(t, "x", "main")
+	p.Run(context.Background(), spec)
+	waitIdle(t, p, spec, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result, err := p.Run(ctx, spec)
+	if err == nil || result.Termination != TerminationTimeout {
+		t.Fatalf("err = %v, termination = %q; want timeout", err, result.Termination)
+	}
+	if result.Stdout != "started" {
+		t.Errorf("stdout = %q", result.Stdout)
+	}
+}
+
+func TestNewPool_RequiresDocker(t *testing.T) {
+	if _, err := NewPool(&localRunner{}, PoolOptions{Size: 1}); err == nil {
+		t.Error("expected error for non-docker runner")
+	}
+}
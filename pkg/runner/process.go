@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// processHooks adapts startProcess to a backend; every field is optional.
+type processHooks struct {
+	timings  PhaseTimings                        // phases measured before the start (the CLI pull); Execute is filled in
+	classify func(code int, stderr string) error // exit codes that mean the runner failed, not the program; stderr is the tail of the output
+	kill     func()                              // ends the program when forwarded signals are ignored; default kills the process
+	cleanup  func()                              // runs after exit
+}
+
+// startProcess wires spec's stdin/stdout/stderr onto cmd, starts it and streams the results.
+// Output not sent to spec's writers goes to the returned channels; all channels close after the
+// process exits and its output has been copied. spec.Signals are delivered to the process, which
+// then runs in its own process group (unless it needs the terminal for spec.TTY) so the terminal
+// does not signal it a second time.
+// Callers set cmd.Cancel/WaitDelay to control what happens when the context is cancelled.
+func startProcess(cmd *exec.Cmd, spec RunSpec, hooks processHooks) (*StreamingResult, error) {
+	stdoutChan := make(chan string, 10)
+	stderrChan := make(chan string, 10)
+	doneChan := make(chan error, 1)
+	exitCodeChan := make(chan int, 1)
+	infoChan := make(chan RunInfo, 1)
+
+	if spec.StdinReader != nil {
+		cmd.Stdin = spec.StdinReader
+	} else if spec.Stdin != "" {
+		cmd.Stdin = bytes.NewBufferString(spec.Stdin)
+	}
+
+	// With non-*os.File writers exec copies output itself and Wait returns only once the copy
+	// is finished, so nothing is lost between exit and channel close.
+	cmd.Stdout = chanWriter(stdoutChan)
+	if spec.Stdout != nil {
+		cmd.Stdout = spec.Stdout
+	}
+	cmd.Stderr = chanWriter(stderrChan)
+	if spec.Stderr != nil {
+		cmd.Stderr = spec.Stderr
+	}
+	var stderrTail tailBuffer
+	if hooks.classify != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &stderrTail)
+	}
+
+	// A TTY child must stay in the foreground group to use the terminal; in raw mode the
+	// terminal sends Ctrl-C as input rather than as a signal anyway.
+	if spec.Signals != nil && !spec.TTY {
+		ownProcessGroup(cmd)
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		if hooks.cleanup != nil {
+			hooks.cleanup()
+		}
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	exited := make(chan struct{})
+	kill := hooks.kill
+	if kill == nil {
+		kill = func() { _ = cmd.Process.Kill() }
+	}
+	forwardSignals(spec, exited, func(sig os.Signal) { _ = cmd.Process.Signal(sig) }, kill)
+
+	go func() {
+		err := cmd.Wait()
+		close(exited)
+		if hooks.cleanup != nil {
+			hooks.cleanup()
+		}
+		// ProcessState holds the real exit status even when Wait reports the context error.
+		code := -1
+		if cmd.ProcessState != nil {
+			code = cmd.ProcessState.ExitCode()
+		}
+		exitCodeChan <- code
+		timings := hooks.timings
+		timings.Execute = time.Since(start)
+		termination := terminationForExit(code)
+		if hooks.classify != nil {
+			if cerr := hooks.classify(code, stderrTail.String()); cerr != nil {
+				err, termination = cerr, TerminationInfraError
+			}
+		}
+		infoChan <- RunInfo{Timings: timings, Termination: termination}
+		doneChan <- err
+		close(stdoutChan)
+		close(stderrChan)
+		close(exitCodeChan)
+		close(infoChan)
+		close(doneChan)
+	}()
+
+	return &StreamingResult{
+		Stdout:   stdoutChan,
+		Stderr:   stderrChan,
+		Done:     doneChan,
+		ExitCode: exitCodeChan,
+		Info:     infoChan,
+	}, nil
+}
+
+// stderrTailBytes is how much of the end of stderr tailBuffer keeps, enough for the runtime's
+// error message.
+const stderrTailBytes = 4 << 10
+
+// tailBuffer keeps the last stderrTailBytes written to it.
+type tailBuffer struct {
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - stderrTailBytes; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
@@ -0,0 +1,193 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ofthemachine/fraglet/pkg/config"
+)
+
+// RunnerEnv selects the runner backend when no explicit name is given.
+const RunnerEnv = "FRAGLET_RUNNER"
+
+// Backend is a runner that can be selected by name.
+type Backend struct {
+	Name       string
+	New        func() Runner
+	Containers bool // runs container images; only these are candidates for container specs
+}
+
+// backends in automatic-selection order, guarded by backendsMu.
+var (
+	backendsMu sync.RWMutex
+	backends   = []Backend{
+		{Name: "docker", New: func() Runner { return &dockerRunner{} }, Containers: true},
+		{Name: "podman", New: func() Runner { return &podmanRunner{} }, Containers: true},
+		{Name: "local", New: func() Runner { return &localRunner{} }},
+	}
+)
+
+// Register adds a backend. Container backends registered later are probed after the built-in ones.
+func Register(b Backend) error {
+	if b.Name == "" || b.New == nil {
+		return fmt.Errorf("runner backend needs a name and constructor")
+	}
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if slices.ContainsFunc(backends, func(o Backend) bool { return o.Name == b.Name }) {
+		return fmt.Errorf("duplicate runner backend: %s", b.Name)
+	}
+	backends = append(backends, b)
+	return nil
+}
+
+// Backends returns the registered backend names in selection order.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, len(backends))
+	for i, b := range backends {
+		names[i] = b.Name
+	}
+	return names
+}
+
+func lookupBackend(name string) (Backend, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	for _, b := range backends {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Backend{}, false
+}
+
+// ForName returns the runner backend with the given name.
+func ForName(name string) (Runner, error) {
+	b, ok := lookupBackend(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown runner %q (expected %s)", name, strings.Join(Backends(), ", "))
+	}
+	return b.New(), nil
+}
+
+// prober is implemented by runners that can explain why they are unavailable.
+type prober interface {
+	Probe() error
+}
+
+// Probe returns nil when r is usable, otherwise the reason it is not.
+func Probe(r Runner) error {
+	if p, ok := r.(prober); ok {
+		return p.Probe()
+	}
+	if !r.Available() {
+		return errors.New("not available")
+	}
+	return nil
+}
+
+// ProbeResult records one candidate tried during automatic selection.
+type ProbeResult struct {
+	Runner string
+	Err    error
+}
+
+// NoRunnerError is returned when no container backend is usable.
+type NoRunnerError struct {
+	Image  string
+	Probes []ProbeResult
+}
+
+func (e *NoRunnerError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "no container runner available to run %s; tried:", e.Image)
+	for _, p := range e.Probes {
+		fmt.Fprintf(&b, "\n  %s: %v", p.Runner, p.Err)
+	}
+	fmt.Fprintf(&b, "\nStart Docker or install Podman, or choose a backend with --runner or %s", RunnerEnv)
+	return b.String()
+}
+
+// Select picks the runner for a spec whose image is container ("" for host commands).
+// The backend comes from name (e.g. --runner), then $FRAGLET_RUNNER, then the config file's
+// runner; otherwise container backends are probed in order. A container spec is never given a
+// backend that would run it on the host, and an explicitly chosen backend that is unusable is an
+// error rather than a reason to try another. Host specs run locally even when a container
+// backend is chosen, since no container backend can run them.
+func Select(name, container string) (Runner, error) {
+	source := "--runner"
+	if name == "" {
+		name, source = os.Getenv(RunnerEnv), RunnerEnv
+	}
+	if name == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		name, source = cfg.Runner, config.Path()
+	}
+
+	if name != "" {
+		b, ok := lookupBackend(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown runner %q from %s (expected %s)", name, source, strings.Join(Backends(), ", "))
+		}
+		if container != "" && !b.Containers {
+			return nil, fmt.Errorf("runner %q (from %s) cannot run container image %s", name, source, container)
+		}
+		if container == "" && b.Containers {
+			return &localRunner{}, nil
+		}
+		r := b.New()
+		if err := Probe(r); err != nil {
+			return nil, errorf(ErrDaemon, "runner %q (from %s) is not usable: %w", name, source, err)
+		}
+		return r, nil
+	}
+
+	if container == "" {
+		return &localRunner{}, nil
+	}
+	noRunner := &NoRunnerError{Image: container}
+	backendsMu.RLock()
+	candidates := slices.Clone(backends)
+	backendsMu.RUnlock()
+	for _, b := range candidates {
+		if !b.Containers {
+			continue
+		}
+		r := b.New()
+		err := Probe(r)
+		if err == nil {
+			return r, nil
+		}
+		noRunner.Probes = append(noRunner.Probes, ProbeResult{Runner: b.Name, Err: err})
+	}
+	return nil, noRunner
+}
+
+// cliProbe checks that bin is on PATH and answers "<bin> version" (i.e. its daemon/service is reachable).
+func cliProbe(bin string) error {
+	if _, err := exec.LookPath(bin); err != nil {
+		return fmt.Errorf("%s not found in PATH", bin)
+	}
+	out, err := exec.Command(bin, "version").CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if i := strings.LastIndex(msg, "\n"); i >= 0 {
+			msg = msg[i+1:]
+		}
+		if msg == "" {
+			return fmt.Errorf("%s version: %w", bin, err)
+		}
+		return fmt.Errorf("%s version: %s", bin, msg)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// isolateSelection clears every selection input and makes docker unusable: the Engine API points
+// at a missing socket and a "docker" script that fails "version" shadows any real CLI.
+func isolateSelection(t *testing.T) {
+	t.Helper()
+	t.Setenv(RunnerEnv, "")
+	t.Setenv("FRAGLET_CONFIG", filepath.Join(t.TempDir(), "missing.yml"))
+	t.Setenv("DOCKER_HOST", "unix://"+filepath.Join(t.TempDir(), "no.sock"))
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'Cannot connect to the Docker daemon' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestSelect_NoBackendNamesProbes(t *testing.T) {
+	isolateSelection(t)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if _, err := os.Stat(filepath.Join(dir, "podman")); err == nil {
+			t.Skip("real podman on PATH")
+		}
+	}
+
+	r, err := Select("", "100hellos/python:latest")
+	var noRunner *NoRunnerError
+	if !errors.As(err, &noRunner) {
+		t.Fatalf("Select = %v, %v; want *NoRunnerError", r, err)
+	}
+	msg := err.Error()
+	for _, want := range []string{"100hellos/python:latest", "docker: ", "Cannot connect to the Docker daemon", "podman: podman not found in PATH"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error missing %q:\n%s", want, msg)
+		}
+	}
+	if len(noRunner.Probes) != 2 {
+		t.Errorf("probes = %+v, want docker and podman only (never local)", noRunner.Probes)
+	}
+}
+
+func TestSelect_Precedence(t *testing.T) {
+	isolateSelection(t)
+	fakePodman(t)
+	const img = "img"
+
+	// Automatic: docker unusable, podman usable.
+	if r, err := Select("", img); err != nil || r.Name() != "podman" {
+		t.Fatalf("auto: %v, %v", r, err)
+	}
+
+	// Config file.
+	cfgPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(cfgPath, []byte("runner: docker\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FRAGLET_CONFIG", cfgPath)
+	if _, err := Select("", img); err == nil || !strings.Contains(err.Error(), cfgPath) {
+		t.Errorf("config runner=docker should fail naming the config file, got %v", err)
+	}
+
+	// Environment beats config.
+	t.Setenv(RunnerEnv, "podman")
+	if r, err := Select("", img); err != nil || r.Name() != "podman" {
+		t.Errorf("env: %v, %v", r, err)
+	}
+
+	// Flag beats environment, and an unusable explicit choice is not replaced by another backend.
+	if _, err := Select("docker", img); err == nil || !strings.Contains(err.Error(), "--runner") {
+		t.Errorf("--runner docker should fail, got %v", err)
+	}
+	if _, err := Select("lxc", img); err == nil || !strings.Contains(err.Error(), "unknown runner") {
+		t.Errorf("unknown runner: %v", err)
+	}
+}
+
+func TestSelect_NeverRunsContainerOnHost(t *testing.T) {
+	isolateSelection(t)
+	if _, err := Select("local", "img"); err == nil || !strings.Contains(err.Error(), "cannot run container image") {
+		t.Errorf("local for a container spec: %v", err)
+	}
+	if r, err := Select("", ""); err != nil || r.Name() != "local" {
+		t.Errorf("host command: %v, %v", r, err)
+	}
+}
+
+func TestSelect_HostSpecWithContainerBackend(t *testing.T) {
+	isolateSelection(t)
+	// docker is unusable here, but host commands never needed it.
+	t.Setenv(RunnerEnv, "docker")
+	if r, err := Select("", ""); err != nil || r.Name() != "local" {
+		t.Errorf("FRAGLET_RUNNER=docker, host command: %v, %v", r, err)
+	}
+	if r, err := Select("podman", ""); err != nil || r.Name() != "local" {
+		t.Errorf("--runner podman, host command: %v, %v", r, err)
+	}
+}
+
+func TestRegister_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Register(Backend{Name: fmt.Sprintf("test-concurrent-%d", i), New: func() Runner { return &localRunner{} }})
+		}()
+		go func() {
+			defer wg.Done()
+			Backends()
+			lookupBackend("docker")
+		}()
+	}
+	wg.Wait()
+	if err := Register(Backend{Name: "test-concurrent-0", New: func() Runner { return &localRunner{} }}); err == nil {
+		t.Error("duplicate backend registered")
+	}
+}
@@ -2,8 +2,15 @@ package runner
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"io"
+	"os"
 	"os/exec"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -31,6 +38,8 @@ type StreamingResult struct {
 	Stderr   <-chan string // Channel for stderr chunks
 	Done     <-chan error  // Channel that closes when command completes, error if non-nil
 	ExitCode <-chan int    // Channel that receives exit code when available
+	// Info optionally receives phase timings and how the run ended; it is sent before Done.
+	Info <-chan RunInfo
 }
 
 // VolumeMount defines a volume mount for container execution.
@@ -43,50 +52,139 @@ type VolumeMount struct {
 
 // RunSpec defines what to execute
 type RunSpec struct {
-	Command     string        // The command to execute (rendered template)
-	Stdin       string        // Optional stdin input (buffered string, for programmatic use)
-	StdinReader io.Reader     // Optional stdin stream (takes precedence over Stdin)
-	Container   string        // Optional container image (e.g., "python:3.11-slim")
-	Entrypoint  string        // Optional entrypoint (e.g., "python" for multiline scripts)
-	Platform    string        // Optional platform (e.g., linux/amd64). Defaults to linux/amd64.
-	Env         []string      // Optional environment variables (for ENVVAR input)
-	WorkDir     string        // Optional working directory
-	Volumes     []VolumeMount // Optional volume mounts
-	Args        []string      // Arguments passed to the command
-	NetworkMode string        // Optional docker --network value (e.g. "none" to disable networking). Empty = docker default. Ignored by the local runner.
-	Stdout      io.Writer     // If non-nil, command stdout is written here; otherwise captured
-	Stderr      io.Writer     // If non-nil, command stderr is written here; otherwise captured
+	Command     string           // The command to execute (rendered template)
+	Stdin       string           // Optional stdin input (buffered string, for programmatic use)
+	StdinReader io.Reader        // Optional stdin stream (takes precedence over Stdin)
+	Container   string           // Optional container image (e.g., "python:3.11-slim")
+	Entrypoint  string           // Optional entrypoint (e.g., "python" for multiline scripts)
+	Platform    string           // Optional platform (e.g., linux/amd64). Defaults to linux/amd64.
+	Env         []string         // Optional environment variables (for ENVVAR input)
+	SecretEnv   []string         // Optional NAME=value variables kept out of every argv: CLI runners pass "-e NAME" and the value through the client's environment
+	WorkDir     string           // Optional working directory
+	Volumes     []VolumeMount    // Optional volume mounts
+	Transport   CodeTransport    // How read-only file Volumes reach the container; auto = bind-mount for a local daemon, copy otherwise
+	Args        []string         // Arguments passed to the command
+	NetworkMode string           // Optional docker --network value (e.g. "none" to disable networking). Empty = docker default. Ignored by the local runner.
+	Stdout      io.Writer        // If non-nil, command stdout is written here; otherwise captured
+	Stderr      io.Writer        // If non-nil, command stderr is written here; otherwise captured
+	Limits      ResourceLimits   // Optional memory/CPU/pids/ulimit caps (container runners) and output cap (Run, all runners)
+	StopGrace   time.Duration    // How long a cancelled or signalled run may take to exit before it is killed; 0 = DefaultStopGrace
+	Signals     <-chan os.Signal // Optional: each signal received is forwarded to the program; it is killed if still running StopGrace after the first
+	TTY         bool             // Allocate a pseudo-terminal for the program (stderr is merged into stdout). Container runners only.
+	TermSizes   <-chan TermSize  // Optional with TTY: initial and changed host terminal sizes to apply to the container's terminal
 	// Note: Executor field removed - Phase 2 feature when executor registry is designed
 }
 
+// TermSize is a terminal size in character cells.
+type TermSize struct {
+	Rows, Cols uint16
+}
+
 // RunResult captures execution output
 type RunResult struct {
-	Stdout   string
-	Stderr   string
-	ExitCode int
-	Duration time.Duration
-}
-
-// NewRunner creates an appropriate runner based on the spec
-// If container is specified, returns a docker runner (if available) or local
-// If no container, returns local runner
-func NewRunner(container, entrypoint string) Runner {
-	if container != "" {
-		docker := &dockerRunner{}
-		if docker.Available() {
-			return docker
-		}
-		// Docker not available, fall back to local (ignores container)
+	Stdout      string
+	Stderr      string
+	ExitCode    int
+	Duration    time.Duration
+	Truncated   bool              // Output exceeded Limits.MaxOutput; the excess was discarded
+	Timings     PhaseTimings      // Where the time went; phases a runner cannot observe stay zero
+	Termination TerminationReason // How the run ended
+}
+
+// PhaseTimings splits a run into image pull, container setup and program execution.
+// The CLI backends cannot separate setup from execution (Execute covers "docker run") and host
+// runs only report Execute.
+type PhaseTimings struct {
+	Pull    time.Duration `json:"pull"`
+	Create  time.Duration `json:"create"`
+	Execute time.Duration `json:"execute"`
+}
+
+// RunInfo is what a runner observed about a run besides its output and exit code.
+type RunInfo struct {
+	Timings     PhaseTimings
+	Termination TerminationReason
+}
+
+// TerminationReason says how a run ended.
+type TerminationReason string
+
+const (
+	TerminationExited     TerminationReason = "exited"      // the program exited on its own
+	TerminationSignaled   TerminationReason = "signaled"    // the program was killed by a signal
+	TerminationOOMKilled  TerminationReason = "oom-killed"  // the container hit its memory limit
+	TerminationTimeout    TerminationReason = "timeout"     // the context deadline passed
+	TerminationCancelled  TerminationReason = "cancelled"   // the context was cancelled
+	TerminationInfraError TerminationReason = "infra-error" // the runner failed (pull, create, daemon), not the program
+)
+
+// DefaultStopGrace is how long a cancelled container gets to exit after its stop signal.
+const DefaultStopGrace = 2 * time.Second
+
+// env returns Env followed by SecretEnv, for runners that never put variables on a command line.
+func (s RunSpec) env() []string {
+	return slices.Concat(s.Env, s.SecretEnv)
+}
+
+func (s RunSpec) stopGrace() time.Duration {
+	if s.StopGrace > 0 {
+		return s.StopGrace
 	}
-	return &localRunner{}
+	return DefaultStopGrace
+}
+
+// newContainerName returns a unique name so a run's container can be found and stopped.
+func newContainerName() string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return "fraglet-" + hex.EncodeToString(b[:])
+}
+
+func terminationFor(err error) TerminationReason {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return TerminationTimeout
+	}
+	return TerminationCancelled
+}
+
+// terminationForExit classifies an exit code: -1 (no status, killed) or 128+n by the shell and
+// container convention for signal n count as signaled.
+func terminationForExit(code int) TerminationReason {
+	if code < 0 || code > 128 {
+		return TerminationSignaled
+	}
+	return TerminationExited
+}
+
+// infraResult is the result of a run that failed before the program started.
+func infraResult() RunResult {
+	return RunResult{ExitCode: -1, Termination: TerminationInfraError}
+}
+
+// NewRunner returns the runner for container ("" for host commands): the backend named by
+// FRAGLET_RUNNER or the config file, otherwise the first usable container backend.
+// It never falls back to running a container spec on the host; see Select.
+func NewRunner(container string) (Runner, error) {
+	return Select("", container)
+}
+
+// withOutputCap applies spec.Limits.MaxOutput to any caller-supplied writers and returns the
+// cap for collectStreamingResults. RunStreaming callers reading the channels enforce their own caps.
+func withOutputCap(spec RunSpec) (RunSpec, *outputCap) {
+	limit := newOutputCap(spec.Limits)
+	spec.Stdout = limit.writer(spec.Stdout)
+	spec.Stderr = limit.writer(spec.Stderr)
+	return spec, limit
 }
 
 // collectStreamingResults collects all output from a streaming execution and returns a RunResult
-// This is used by Run() implementations to convert RunStreaming() results to RunResult
-func collectStreamingResults(ctx context.Context, streaming *StreamingResult) (RunResult, error) {
+// This is used by Run() implementations to convert RunStreaming() results to RunResult.
+// Output beyond limit is drained and discarded so the program never blocks on a full pipe.
+func collectStreamingResults(ctx context.Context, streaming *StreamingResult, limit *outputCap, grace time.Duration) (RunResult, error) {
 	start := time.Now()
 
-	var stdout, stderr string
+	var mu sync.Mutex // guards stdout and stderr, which an abandoned run may still write
+	var stdout, stderr strings.Builder
 	var exitCode int
 	var execErr error
 
@@ -95,7 +193,9 @@ func collectStreamingResults(ctx context.Context, streaming *StreamingResult) (R
 	go func() {
 		defer close(stdoutDone)
 		for chunk := range streaming.Stdout {
-			stdout += chunk
+			mu.Lock()
+			stdout.Write(limit.take([]byte(chunk)))
+			mu.Unlock()
 		}
 	}()
 
@@ -104,16 +204,42 @@ func collectStreamingResults(ctx context.Context, streaming *StreamingResult) (R
 	go func() {
 		defer close(stderrDone)
 		for chunk := range streaming.Stderr {
-			stderr += chunk
+			mu.Lock()
+			stderr.Write(limit.take([]byte(chunk)))
+			mu.Unlock()
 		}
 	}()
 
-	// Wait for command to complete and get exit code
+	// Wait for command to complete and get exit code. On cancellation the runner stops the
+	// process or container; wait for that too so nothing outlives the returned result, but no
+	// longer than the stop grace plus removeTimeout, in case the daemon never answers.
+	abandoned := false
 	select {
-	case err := <-streaming.Done:
-		execErr = err
+	case execErr = <-streaming.Done:
 	case <-ctx.Done():
-		execErr = ctx.Err()
+		stopWait := time.NewTimer(grace + removeTimeout)
+		select {
+		case execErr = <-streaming.Done:
+		case <-stopWait.C:
+			execErr, abandoned = ctx.Err(), true
+		}
+		stopWait.Stop()
+	}
+	var info RunInfo
+	if streaming.Info != nil {
+		select {
+		case info = <-streaming.Info:
+		default:
+		}
+	}
+	// A run that failed because ctx ended is reported as terminated, whichever channel fired first.
+	if ctxErr := ctx.Err(); ctxErr != nil && execErr != nil {
+		info.Termination = terminationFor(ctxErr)
+		kind := ErrCancelled
+		if info.Termination == TerminationTimeout {
+			kind = ErrTimeout
+		}
+		execErr = wrapErr(kind, ctxErr)
 	}
 
 	// Read exit code
@@ -128,14 +254,24 @@ func collectStreamingResults(ctx context.Context, streaming *StreamingResult) (R
 	}
 
 	// Wait for output collection to finish
-	<-stdoutDone
-	<-stderrDone
+	if !abandoned {
+		<-stdoutDone
+		<-stderrDone
+	}
 
+	mu.Lock()
 	result := RunResult{
-		Stdout:   stdout,
-		Stderr:   stderr,
-		ExitCode: exitCode,
-		Duration: time.Since(start),
+		Stdout:      stdout.String(),
+		Stderr:      stderr.String(),
+		ExitCode:    exitCode,
+		Duration:    time.Since(start),
+		Truncated:   limit.Truncated(),
+		Timings:     info.Timings,
+		Termination: info.Termination,
+	}
+	mu.Unlock()
+	if result.Termination == "" {
+		result.Termination = terminationForExit(exitCode)
 	}
 
 	// Only return error for actual execution failures, not for non-zero exit codes
@@ -146,6 +282,9 @@ func collectStreamingResults(ctx context.Context, streaming *StreamingResult) (R
 			return result, nil
 		}
 		// For other errors (context cancelled, command not found, etc.), return the error
+		if ctx.Err() == nil {
+			result.Termination = TerminationInfraError
+		}
 		return result, execErr
 	}
 
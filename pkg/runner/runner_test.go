@@ -2,12 +2,16 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
 
 func TestNewRunner_Local(t *testing.T) {
-	r := NewRunner("", "")
+	r, err := NewRunner("")
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
 	if r.Name() != "local" {
 		t.Errorf("Expected local runner, got %s", r.Name())
 	}
@@ -17,20 +21,19 @@ func TestNewRunner_Local(t *testing.T) {
 }
 
 func TestNewRunner_Docker(t *testing.T) {
-	// This will return docker if available, otherwise local
-	r := NewRunner("python:3.11-slim", "")
+	t.Setenv(RunnerEnv, "")
+	t.Setenv("FRAGLET_CONFIG", "/nonexistent/config.yml")
+	r, err := NewRunner("python:3.11-slim")
 
 	// Check if docker is available
 	docker := &dockerRunner{}
 	if docker.Available() {
-		if r.Name() != "docker" {
-			t.Errorf("Expected docker runner when docker is available, got %s", r.Name())
-		}
-	} else {
-		// Docker not available, should fall back to local
-		if r.Name() != "local" {
-			t.Errorf("Expected local runner when docker unavailable, got %s", r.Name())
+		if err != nil || r.Name() != "docker" {
+			t.Errorf("Expected docker runner when docker is available, got %v, %v", r, err)
 		}
+	} else if err == nil && r.Name() == "local" {
+		// Never fall back to running a container spec on the host
+		t.Errorf("Expected no local fallback when docker is unavailable")
 	}
 }
 
@@ -167,3 +170,32 @@ func TestLocalRunner_RunStreaming(t *testing.T) {
 		t.Error("Expected stdout output")
 	}
 }
+
+func TestCollectStreamingResults_StopWaitBounded(t *testing.T) {
+	// A runner whose stop never completes, such as a hung daemon.
+	streaming := &StreamingResult{
+		Stdout:   make(chan string),
+		Stderr:   make(chan string),
+		Done:     make(chan error),
+		ExitCode: make(chan int),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	saved := removeTimeout
+	removeTimeout = 30 * time.Millisecond
+	t.Cleanup(func() { removeTimeout = saved })
+	_, limit := withOutputCap(RunSpec{})
+	const grace = 20 * time.Millisecond
+	start := time.Now()
+	_, err := collectStreamingResults(ctx, streaming, limit, grace)
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("err = %v, want ErrCancelled", err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < grace+removeTimeout {
+		t.Errorf("returned after %v, before the %v wait budget", elapsed, grace+removeTimeout)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// forwardSignals delivers spec.Signals to the running program until done closes. A program still
+// running stopGrace after the first signal is ended with kill. No-op when spec.Signals is nil.
+func forwardSignals(spec RunSpec, done <-chan struct{}, deliver func(os.Signal), kill func()) {
+	if spec.Signals == nil {
+		return
+	}
+	go func() {
+		signals := spec.Signals
+		var escalate <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					signals = nil
+					continue
+				}
+				deliver(sig)
+				if escalate == nil {
+					escalate = time.After(spec.stopGrace())
+				}
+			case <-escalate:
+				kill()
+				return
+			}
+		}
+	}()
+}
+
+// signalNumber returns sig's number, or 0 for signals that are not syscall.Signal values.
+func signalNumber(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return int(s)
+	}
+	return 0
+}
+
+// signalArg formats sig for "docker kill --signal" and the kill endpoint, which take numbers.
+func signalArg(sig os.Signal) string {
+	if n := signalNumber(sig); n > 0 {
+		return strconv.Itoa(n)
+	}
+	return "SIGTERM"
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+)
+
+// CodeTransport selects how read-only file Volumes (the fraglet itself) reach a container.
+type CodeTransport string
+
+const (
+	// TransportAuto bind-mounts when the daemon runs on this machine and copies otherwise.
+	TransportAuto CodeTransport = ""
+	// TransportBind bind-mounts host paths; the daemon must see the host filesystem.
+	TransportBind CodeTransport = "bind"
+	// TransportCopy uploads the files into the created container before it starts, so it works
+	// with remote DOCKER_HOSTs, docker contexts pointing at a VM and docker-in-docker CI.
+	TransportCopy CodeTransport = "copy"
+)
+
+// ParseTransport parses "auto", "bind" or "copy"; "" is auto.
+func ParseTransport(s string) (CodeTransport, error) {
+	switch s {
+	case "", "auto":
+		return TransportAuto, nil
+	case string(TransportBind), string(TransportCopy):
+		return CodeTransport(s), nil
+	}
+	return "", fmt.Errorf("invalid transport %q (use auto, bind or copy)", s)
+}
+
+// copyFiles resolves t for a daemon reached at host.
+func (t CodeTransport) copyFiles(host string) bool {
+	if t == TransportAuto {
+		return !dockerapi.IsLocalHost(host)
+	}
+	return t == TransportCopy
+}
+
+// bindHint suggests the copy transport on an error from a run that bind-mounted host paths: a
+// daemon that does not see this machine's filesystem (docker-out-of-docker, a VM) fails them.
+func bindHint(err error, bound bool) error {
+	if err == nil || !bound {
+		return err
+	}
+	return fmt.Errorf("%w\nhost paths were bind-mounted; if the daemon cannot see this machine's files (e.g. docker-out-of-docker), retry with --transport=copy", err)
+}
+
+// splitVolumes separates the volumes to upload from those to bind-mount. Only read-only regular
+// files are copied: writable mounts exist to bring results back, which a copy cannot do.
+func splitVolumes(volumes []VolumeMount, copyFiles bool) (binds, copies []VolumeMount) {
+	for _, v := range volumes {
+		if copyFiles && copyable(v) {
+			copies = append(copies, v)
+		} else {
+			binds = append(binds, v)
+		}
+	}
+	return binds, copies
+}
+
+func copyable(v VolumeMount) bool {
+	if v.Writable {
+		return false
+	}
+	fi, err := os.Stat(v.HostPath)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// cliHost returns the daemon endpoint a docker-compatible CLI talks to.
+func cliHost(bin string) string {
+	if bin == "podman" {
+		// Podman runs locally unless pointed at a service; its machine VMs mount the host home.
+		if host := os.Getenv("CONTAINER_HOST"); host != "" {
+			return host
+		}
+		return "unix:///run/podman/podman.sock"
+	}
+	return dockerapi.EnvHost()
+}
@@ -16,6 +16,7 @@ const fragletMetaPrefix = "# fraglet-meta:"
 // ArtifactSaver persists a rendered fraglet artifact content-addressed by lang and hash.
 // Implementations may be synchronous (e.g. local filesystem) or asynchronous (e.g. HTTP);
 // callers must not assume durability. Used when MCP run is started with --save.
+// Artifacts hold the code only, never param or env values; callers mask secrets in body first.
 type ArtifactSaver interface {
 	Save(ctx context.Context, lang string, imageWithDigest string, mode string, annotations []string, body string) error
 }
@@ -0,0 +1,166 @@
+package vein
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions bounds the names a NotFoundError offers.
+const maxSuggestions = 3
+
+// NotFoundError reports a name that is neither a vein nor an alias, with the closest known names.
+type NotFoundError struct {
+	Name        string
+	Suggestions []string // closest vein names and aliases by edit distance, nearest first
+	DisabledBy  *Source  // set when a layer disabled a vein of this name
+}
+
+func (e *NotFoundError) Error() string {
+	msg := "vein not found: " + e.Name
+	switch {
+	case e.DisabledBy != nil:
+		msg += " (disabled by " + e.DisabledBy.String() + ")"
+	case len(e.Suggestions) > 0:
+		msg += " (did you mean " + strings.Join(e.Suggestions, ", ") + "?)"
+	}
+	return msg
+}
+
+// AddAlias maps alias to the vein name (or alias) target, ahead of the aliases veins declare.
+// The alias may not be a vein name itself.
+func (r *VeinRegistry) AddAlias(alias, target string) error {
+	key := strings.ToLower(alias)
+	if key == "" {
+		return fmt.Errorf("empty alias for %s", target)
+	}
+	if _, ok := r.byFoldedName(key); ok {
+		return fmt.Errorf("alias %s: already a vein name", alias)
+	}
+	v, err := r.Resolve(target)
+	if err != nil {
+		return fmt.Errorf("alias %s: %w", alias, err)
+	}
+	r.aliases[key] = v.Name
+	return nil
+}
+
+// Resolve finds a vein by name or alias, ignoring case. Names win over aliases, and aliases
+// added with AddAlias over those declared in veins.yml. An unknown name yields a *NotFoundError.
+func (r *VeinRegistry) Resolve(name string) (*Vein, error) {
+	if v, ok := r.veins[name]; ok {
+		return v, nil
+	}
+	key := strings.ToLower(name)
+	if v, ok := r.byFoldedName(key); ok {
+		return v, nil
+	}
+	if target, ok := r.aliases[key]; ok {
+		if v, ok := r.veins[target]; ok {
+			return v, nil
+		}
+	}
+	for _, n := range r.List() {
+		for _, a := range r.veins[n].Aliases {
+			if strings.ToLower(a) == key {
+				return r.veins[n], nil
+			}
+		}
+	}
+	err := &NotFoundError{Name: name, Suggestions: r.suggest(key)}
+	if src, ok := r.disabled[name]; ok {
+		err.DisabledBy = &src
+	}
+	return nil, err
+}
+
+// byFoldedName finds the vein whose lowercased name is key. When names differ only in case,
+// the first in sorted order wins, so lookups are stable across runs.
+func (r *VeinRegistry) byFoldedName(key string) (*Vein, bool) {
+	for _, name := range r.List() {
+		if strings.ToLower(name) == key {
+			return r.veins[name], true
+		}
+	}
+	return nil, false
+}
+
+// checkAliases rejects a veins.yml alias that is another vein's name or that two veins declare.
+func (r *VeinRegistry) checkAliases() error {
+	owner := make(map[string]string)
+	for _, name := range r.List() {
+		for _, a := range r.veins[name].Aliases {
+			key := strings.ToLower(a)
+			if other, ok := r.byFoldedName(key); ok {
+				return fmt.Errorf("vein %s: alias %s is the name of vein %s", name, a, other.Name)
+			}
+			if prev, ok := owner[key]; ok {
+				return fmt.Errorf("vein %s: alias %s is also declared by vein %s", name, a, prev)
+			}
+			owner[key] = name
+		}
+	}
+	return nil
+}
+
+// suggest returns the vein names and aliases closest to key, allowing about one edit per
+// three characters.
+func (r *VeinRegistry) suggest(key string) []string {
+	limit := max(1, (len(key)+1)/3)
+	type candidate struct {
+		name string
+		dist int
+	}
+	var found []candidate
+	seen := make(map[string]bool)
+	consider := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if d := editDistance(key, strings.ToLower(name)); d <= limit {
+			found = append(found, candidate{name, d})
+		}
+	}
+	for _, name := range r.List() {
+		consider(name)
+		for _, a := range r.veins[name].Aliases {
+			consider(a)
+		}
+	}
+	for a := range r.aliases {
+		consider(a)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].name < found[j].name
+	})
+	var out []string
+	for i := 0; i < len(found) && i < maxSuggestions; i++ {
+		out = append(out, found[i].name)
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b, by byte.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,150 @@
+package vein
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func aliasRegistry(t *testing.T) *VeinRegistry {
+	t.Helper()
+	r := NewVeinRegistry()
+	for _, v := range []*Vein{
+		{Name: "python", Container: "p", Aliases: []string{"py", "python3"}},
+		{Name: "javascript", Container: "j", Aliases: []string{"js"}},
+		{Name: "cpp", Container: "c", Aliases: []string{"c++"}},
+		{Name: "golang", Container: "g"},
+		{Name: "ruby", Container: "r"},
+	} {
+		if err := r.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestResolve(t *testing.T) {
+	r := aliasRegistry(t)
+	if err := r.AddAlias("go", "golang"); err != nil {
+		t.Fatal(err)
+	}
+	for input, want := range map[string]string{
+		"python": "python", "Python": "python", "PY": "python", "python3": "python",
+		"js": "javascript", "c++": "cpp", "C++": "cpp", "go": "golang", "Go": "golang",
+	} {
+		v, err := r.Resolve(input)
+		if err != nil || v.Name != want {
+			t.Errorf("Resolve(%q) = %v, %v; want %s", input, v, err, want)
+		}
+	}
+}
+
+func TestResolve_CaseCollisionIsStable(t *testing.T) {
+	r := NewVeinRegistry()
+	for _, name := range []string{"lisp", "LISP", "Lisp"} {
+		if err := r.Add(&Vein{Name: name, Container: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Exact names resolve to themselves; any other case picks the first name in sorted order.
+	for i := 0; i < 20; i++ {
+		if v, err := r.Resolve("lisP"); err != nil || v.Name != "LISP" {
+			t.Fatalf("Resolve(lisP) = %v, %v; want LISP", v, err)
+		}
+		if v, err := r.Resolve("Lisp"); err != nil || v.Name != "Lisp" {
+			t.Fatalf("Resolve(Lisp) = %v, %v; want Lisp", v, err)
+		}
+	}
+}
+
+func TestResolve_NotFound(t *testing.T) {
+	r := aliasRegistry(t)
+	_, err := r.Resolve("pyhton")
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || !reflect.DeepEqual(nf.Suggestions, []string{"python"}) {
+		t.Fatalf("Resolve(pyhton) = %v", err)
+	}
+	if got, want := err.Error(), "vein not found: pyhton (did you mean python?)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	_, err = r.Resolve("rubby")
+	if err == nil || !strings.HasSuffix(err.Error(), "(did you mean ruby?)") {
+		t.Errorf("Resolve(rubby) = %v", err)
+	}
+
+	_, err = r.Resolve("nonexistent")
+	if err == nil || err.Error() != "vein not found: nonexistent" {
+		t.Errorf("Resolve(nonexistent) = %v", err)
+	}
+}
+
+func TestResolve_Disabled(t *testing.T) {
+	root := isolate(t)
+	writeFile(t, filepath.Join(root, "config", "veins.yml"), "veins:\n  - name: cobol\n    disabled: true\n")
+	r, err := LoadAuto(embeddedStub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Resolve("cobol")
+	if err == nil || !strings.Contains(err.Error(), "vein not found: cobol (disabled by user (") {
+		t.Errorf("Resolve(cobol) = %v", err)
+	}
+}
+
+func TestAddAlias_Errors(t *testing.T) {
+	r := aliasRegistry(t)
+	if err := r.AddAlias("Ruby", "python"); err == nil || !strings.Contains(err.Error(), "already a vein name") {
+		t.Errorf("alias over a vein name: %v", err)
+	}
+	if err := r.AddAlias("rs", "rust"); err == nil || !strings.Contains(err.Error(), "vein not found: rust") {
+		t.Errorf("alias to unknown vein: %v", err)
+	}
+}
+
+func TestLoadAuto_ConfigAliases(t *testing.T) {
+	root := isolate(t)
+	writeFile(t, filepath.Join(root, "config", "config.yml"), "aliases:\n  snake: python\n")
+	r, err := LoadAuto(embeddedStub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := r.Resolve("Snake"); err != nil || v.Name != "python" {
+		t.Errorf("Resolve(Snake) = %v, %v", v, err)
+	}
+
+	writeFile(t, filepath.Join(root, "config", "config.yml"), "aliases:\n  snake: pyton\n")
+	if _, err := LoadAuto(embeddedStub); err == nil || !strings.Contains(err.Error(), "alias snake: vein not found: pyton (did you mean python?)") {
+		t.Errorf("bad config alias: %v", err)
+	}
+}
+
+func TestLoadAuto_AliasCollisions(t *testing.T) {
+	root := isolate(t)
+	user := filepath.Join(root, "config", "veins.yml")
+
+	writeFile(t, user, "veins:\n  - name: python\n    aliases: [C]\n")
+	if _, err := LoadAuto(embeddedStub); err == nil || !strings.Contains(err.Error(), "alias C is the name of vein c") {
+		t.Errorf("alias naming another vein: %v", err)
+	}
+
+	writeFile(t, user, "veins:\n  - name: python\n    aliases: [x]\n  - name: c\n    aliases: [x]\n")
+	if _, err := LoadAuto(embeddedStub); err == nil || !strings.Contains(err.Error(), "alias x is also declared by vein") {
+		t.Errorf("alias declared twice: %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3}, {"python", "python", 0}, {"pyhton", "python", 2}, {"rubby", "ruby", 1}, {"kitten", "sitting", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
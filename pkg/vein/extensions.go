@@ -100,6 +100,11 @@ func (m *ExtensionMap) VeinForExtension(ext string) (string, error) {
 	return vein, nil
 }
 
+// Conflicts returns every vein that claims ext, sorted, when more than one does; nil otherwise.
+func (m *ExtensionMap) Conflicts(ext string) []string {
+	return m.extConflicts[normalizeExtension(ext)]
+}
+
 // VeinForFile extracts extension from filename and returns the vein name
 func (m *ExtensionMap) VeinForFile(filename string) (string, error) {
 	ext := filepath.Ext(filename)
@@ -0,0 +1,204 @@
+package vein
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ofthemachine/fraglet/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Layers of the vein registry, in the order LoadAuto applies them.
+const (
+	LayerEmbedded = "embedded" // veins.yml compiled into the binary
+	LayerUser     = "user"     // veins.yml next to the user's config.yml
+	LayerProject  = "project"  // .fraglet/veins.yml in the working directory or a parent
+	LayerEnv      = "env"      // FRAGLET_VEINS_PATH (file or directory)
+)
+
+// ProjectVeinsFile is the project layer, relative to the directory it is found in.
+const ProjectVeinsFile = ".fraglet/veins.yml"
+
+// Source records a layer that defined or changed a vein.
+type Source struct {
+	Layer string `json:"layer"`          // LayerEmbedded, LayerUser, LayerProject or LayerEnv
+	Path  string `json:"path,omitempty"` // file the entry came from; empty for embedded veins
+}
+
+func (s Source) String() string {
+	if s.Path == "" {
+		return s.Layer
+	}
+	return s.Layer + " (" + s.Path + ")"
+}
+
+// layerEntry is one veins: entry of an overlay layer. A name that an earlier layer defined
+// overrides the fields that are set (limits field by field); disabled removes the vein.
+type layerEntry struct {
+	Vein     `yaml:",inline"`
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// layerFile is one parsed file of a layer.
+type layerFile struct {
+	path    string
+	entries []layerEntry
+}
+
+// UserVeinsPath returns the user layer file: veins.yml in the directory of config.Path().
+func UserVeinsPath() string {
+	p := config.Path()
+	if p == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(p), "veins.yml")
+}
+
+// FindProjectVeins returns the nearest ProjectVeinsFile from dir upwards, or "" when there is none.
+func FindProjectVeins(dir string) string {
+	for {
+		p := filepath.Join(dir, ProjectVeinsFile)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// overlay applies the veins of one layer on top of the registry. Entries for new names must be
+// complete veins; entries for existing names change only the fields they set; disabled: true
+// removes a vein. A name may appear only once within a layer.
+func (r *VeinRegistry) overlay(layer string, files []layerFile) error {
+	seen := make(map[string]string) // name -> file within this layer
+	for _, f := range files {
+		for _, e := range f.entries {
+			if e.Name == "" {
+				return fmt.Errorf("%s: vein name is required", f.path)
+			}
+			if prev, dup := seen[e.Name]; dup {
+				if prev == f.path {
+					return fmt.Errorf("%s: duplicate vein name: %s", f.path, e.Name)
+				}
+				return fmt.Errorf("%s: duplicate vein name: %s (also in %s)", f.path, e.Name, prev)
+			}
+			seen[e.Name] = f.path
+			if err := r.apply(Source{Layer: layer, Path: f.path}, e); err != nil {
+				return fmt.Errorf("%s: %w", f.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// apply merges one layer entry into the registry.
+func (r *VeinRegistry) apply(src Source, e layerEntry) error {
+	existing, ok := r.veins[e.Name]
+	if e.Disabled {
+		delete(r.veins, e.Name)
+		delete(r.sources, e.Name)
+		r.disabled[e.Name] = src
+		return nil
+	}
+	if !ok {
+		v := e.Vein
+		if err := r.Add(&v); err != nil {
+			return fmt.Errorf("invalid vein %s: %w", e.Name, err)
+		}
+		delete(r.disabled, e.Name)
+		r.sources[e.Name] = []Source{src}
+		return nil
+	}
+	merged := *existing
+	if e.Container != "" {
+		merged.Container = e.Container
+	}
+	if len(e.Extensions) > 0 {
+		merged.Extensions = e.Extensions
+	}
+	merged.Limits = merged.Limits.Merge(e.Limits)
+	if e.Description != "" {
+		merged.Description = e.Description
+	}
+	if len(e.Tags) > 0 {
+		merged.Tags = e.Tags
+	}
+	if len(e.Aliases) > 0 {
+		merged.Aliases = e.Aliases
+	}
+	if len(e.Modes) > 0 {
+		merged.Modes = e.Modes
+	}
+	if e.Platform != "" {
+		merged.Platform = e.Platform
+	}
+	if e.Network != "" {
+		merged.Network = e.Network
+	}
+	if e.Homepage != "" {
+		merged.Homepage = e.Homepage
+	}
+	if err := merged.Validate(); err != nil {
+		return err
+	}
+	r.veins[e.Name] = &merged
+	r.sources[e.Name] = append(r.sources[e.Name], src)
+	return nil
+}
+
+// Sources returns the layers that shaped a vein: the first defined it, later ones overrode
+// some of its fields. Veins added directly with Add have none.
+func (r *VeinRegistry) Sources(name string) []Source {
+	return r.sources[name]
+}
+
+// DisabledBy reports the layer that disabled a vein an earlier layer defined.
+func (r *VeinRegistry) DisabledBy(name string) (Source, bool) {
+	src, ok := r.disabled[name]
+	return src, ok
+}
+
+// readLayer parses a layer given as a veins file or a directory of .yml/.yaml files.
+func readLayer(path string) ([]layerFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat veins path %s: %w", path, err)
+	}
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", path, err)
+		}
+		paths = nil
+		for _, entry := range entries {
+			if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no YAML files found in %s", path)
+		}
+		sort.Strings(paths)
+	}
+	files := make([]layerFile, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		var cfg struct {
+			Veins []layerEntry `yaml:"veins"`
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+		files = append(files, layerFile{path: p, entries: cfg.Veins})
+	}
+	return files, nil
+}
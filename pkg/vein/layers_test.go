@@ -0,0 +1,170 @@
+package vein
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ofthemachine/fraglet/pkg/config"
+)
+
+func embeddedStub() (*VeinRegistry, error) {
+	r := NewVeinRegistry()
+	for _, v := range []*Vein{
+		{Name: "python", Container: "100hellos/python:latest", Extensions: []string{".py"}},
+		{Name: "c", Container: "100hellos/c:latest", Extensions: []string{".c"}},
+		{Name: "cobol", Container: "100hellos/cobol:latest", Extensions: []string{".cob"}},
+	} {
+		if err := r.Add(v); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// isolate points every layer at an empty temporary tree and returns its root.
+func isolate(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv(config.PathEnv, filepath.Join(root, "config", "config.yml"))
+	t.Setenv(VeinsPathEnvVar, "")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(root, "project", "sub")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return root
+}
+
+func TestLoadAuto_Layers(t *testing.T) {
+	root := isolate(t)
+	userFile := filepath.Join(root, "config", "veins.yml")
+	projectFile := filepath.Join(root, "project", ProjectVeinsFile)
+	envFile := filepath.Join(root, "env.yml")
+
+	writeFile(t, userFile, `veins:
+  - name: inhouse
+    container: registry.local/inhouse:1
+    extensions: [".ih"]
+  - name: python
+    limits: {memory: 256m}
+    description: Python 3 with numpy
+    modes: [main, repl]
+`)
+	writeFile(t, projectFile, `veins:
+  - name: python
+    container: 100hellos/python:pinned
+  - name: cobol
+    disabled: true
+`)
+	writeFile(t, envFile, `veins:
+  - name: inhouse
+    limits: {cpus: "2"}
+`)
+	t.Setenv(VeinsPathEnvVar, envFile)
+
+	r, err := LoadAuto(embeddedStub)
+	if err != nil {
+		t.Fatalf("LoadAuto: %v", err)
+	}
+
+	py, _ := r.Get("python")
+	if py.Container != "100hellos/python:pinned" || py.Limits.Memory != "256m" || !reflect.DeepEqual(py.Extensions, []string{".py"}) ||
+		py.Description != "Python 3 with numpy" || !reflect.DeepEqual(py.Modes, []string{"main", "repl"}) {
+		t.Errorf("python = %+v", py)
+	}
+	want := []Source{{Layer: LayerEmbedded}, {LayerUser, userFile}, {LayerProject, projectFile}}
+	if got := r.Sources("python"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sources(python) = %v, want %v", got, want)
+	}
+
+	ih, ok := r.Get("inhouse")
+	if !ok || ih.Container != "registry.local/inhouse:1" || ih.Limits.CPUs != "2" {
+		t.Errorf("inhouse = %+v", ih)
+	}
+	if got := r.Sources("inhouse"); len(got) != 2 || got[0].Layer != LayerUser || got[1].Layer != LayerEnv {
+		t.Errorf("Sources(inhouse) = %v", got)
+	}
+
+	if _, ok := r.Get("cobol"); ok {
+		t.Error("cobol should be disabled")
+	}
+	if src, ok := r.DisabledBy("cobol"); !ok || src.Layer != LayerProject {
+		t.Errorf("DisabledBy(cobol) = %v, %v", src, ok)
+	}
+
+	if got := r.Sources("c"); !reflect.DeepEqual(got, []Source{{Layer: LayerEmbedded}}) {
+		t.Errorf("Sources(c) = %v", got)
+	}
+}
+
+func TestLoadAuto_NoLayers(t *testing.T) {
+	isolate(t)
+	r, err := LoadAuto(embeddedStub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.List()) != 3 {
+		t.Errorf("List() = %v", r.List())
+	}
+}
+
+func TestLoadAuto_DuplicateWithinLayer(t *testing.T) {
+	root := isolate(t)
+	dir := filepath.Join(root, "veins.d")
+	writeFile(t, filepath.Join(dir, "a.yml"), "veins:\n  - name: python\n    container: a/python\n")
+	writeFile(t, filepath.Join(dir, "b.yml"), "veins:\n  - name: python\n    container: b/python\n")
+	t.Setenv(VeinsPathEnvVar, dir)
+
+	_, err := LoadAuto(embeddedStub)
+	if err == nil || !strings.Contains(err.Error(), "duplicate vein name: python") {
+		t.Fatalf("err = %v, want duplicate vein name", err)
+	}
+}
+
+func TestLoadAuto_NewVeinNeedsContainer(t *testing.T) {
+	root := isolate(t)
+	writeFile(t, filepath.Join(root, "config", "veins.yml"), "veins:\n  - name: brandnew\n    extensions: [\".bn\"]\n")
+
+	_, err := LoadAuto(embeddedStub)
+	if err == nil || !strings.Contains(err.Error(), "vein container is required") {
+		t.Fatalf("err = %v, want container required", err)
+	}
+}
+
+func TestLoadAuto_MissingEnvPath(t *testing.T) {
+	root := isolate(t)
+	t.Setenv(VeinsPathEnvVar, filepath.Join(root, "nope.yml"))
+	if _, err := LoadAuto(embeddedStub); err == nil {
+		t.Fatal("expected error for missing FRAGLET_VEINS_PATH")
+	}
+}
+
+func TestLoadAuto_OverrideIsValidated(t *testing.T) {
+	root := isolate(t)
+	writeFile(t, filepath.Join(root, "config", "veins.yml"), "veins:\n  - name: python\n    homepage: python.org\n")
+
+	_, err := LoadAuto(embeddedStub)
+	if err == nil || !strings.Contains(err.Error(), "invalid homepage") {
+		t.Fatalf("err = %v, want invalid homepage", err)
+	}
+}
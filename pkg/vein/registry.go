@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/ofthemachine/fraglet/pkg/config"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	// VeinsPathEnvVar is the environment variable that, when set, makes the system
-	// use filesystem veins instead of embedded ones. Can be a file or directory path.
+	// VeinsPathEnvVar is the environment variable naming the last (highest-priority) layer of
+	// veins, applied over the embedded, user and project layers. Can be a file or directory path.
 	// This is useful for development.
 	VeinsPathEnvVar = "FRAGLET_VEINS_PATH"
 )
@@ -91,29 +93,69 @@ func LoadFromDir(dir string) (*VeinRegistry, error) {
 	return registry, nil
 }
 
-// LoadAuto loads veins, checking FRAGLET_VEINS_PATH first, then falling back to embedded
-// The path can be either a file or a directory.
+// LoadAuto loads the layered registry: the embedded veins, then the user layer (UserVeinsPath),
+// then the project layer (FindProjectVeins from the working directory), then FRAGLET_VEINS_PATH.
+// Missing user and project files are skipped; FRAGLET_VEINS_PATH, when set, must exist and may be
+// a file or a directory. Each layer adds, overrides or disables veins of the ones before it; see
+// Sources for where a vein came from. The "aliases:" map of config.yml is added last.
 // Extension conflicts are warned only when encountered (via ExtensionMap.VeinForExtension).
 func LoadAuto(loadEmbedded func() (*VeinRegistry, error)) (*VeinRegistry, error) {
-	var registry *VeinRegistry
-	var err error
-	veinsPath := os.Getenv(VeinsPathEnvVar)
-	if veinsPath != "" {
-		// Check if path is a file or directory
-		info, err := os.Stat(veinsPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to stat veins path %s: %w", veinsPath, err)
+	registry, err := loadEmbedded()
+	if err != nil {
+		return nil, err
+	}
+	for name := range registry.veins {
+		if len(registry.sources[name]) == 0 {
+			registry.sources[name] = []Source{{Layer: LayerEmbedded}}
+		}
+	}
+
+	layers := []struct{ name, path string }{{LayerUser, UserVeinsPath()}}
+	if wd, err := os.Getwd(); err == nil {
+		layers = append(layers, struct{ name, path string }{LayerProject, FindProjectVeins(wd)})
+	}
+	for _, l := range layers {
+		if l.path == "" {
+			continue
 		}
-		if info.IsDir() {
-			registry, err = LoadFromDir(veinsPath)
-		} else {
-			registry, err = LoadFromFile(veinsPath)
+		if _, err := os.Stat(l.path); os.IsNotExist(err) {
+			continue
+		}
+		if err := registry.overlayPath(l.name, l.path); err != nil {
+			return nil, err
 		}
-	} else {
-		registry, err = loadEmbedded()
 	}
+	if veinsPath := os.Getenv(VeinsPathEnvVar); veinsPath != "" {
+		if err := registry.overlayPath(LayerEnv, veinsPath); err != nil {
+			return nil, err
+		}
+	}
+	if err := registry.checkAliases(); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
 	if err != nil {
 		return nil, err
 	}
+	aliases := make([]string, 0, len(cfg.Aliases))
+	for alias := range cfg.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if err := registry.AddAlias(alias, cfg.Aliases[alias]); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Path(), err)
+		}
+	}
 	return registry, nil
 }
+
+// overlayPath reads a layer file or directory and applies it.
+func (r *VeinRegistry) overlayPath(layer, path string) error {
+	files, err := readLayer(path)
+	if err != nil {
+		return err
+	}
+	return r.overlay(layer, files)
+}
@@ -3,39 +3,125 @@ package vein
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"unicode"
+
+	"github.com/ofthemachine/fraglet/pkg/dockerapi"
+	"github.com/ofthemachine/fraglet/pkg/runner"
 )
 
 // Vein defines an injection point for fraglet code
 type Vein struct {
-	Name       string   `yaml:"name"`                 // Vein name (e.g., "python", "c")
-	Container  string   `yaml:"container"`            // Container image (required)
-	Extensions []string `yaml:"extensions,omitempty"` // File extensions that map to this vein (e.g., [".py"])
+	Name        string                `yaml:"name"`                  // Vein name (e.g., "python", "c")
+	Container   string                `yaml:"container"`             // Container image (required)
+	Extensions  []string              `yaml:"extensions,omitempty"`  // File extensions that map to this vein (e.g., [".py"])
+	Limits      runner.ResourceLimits `yaml:"limits,omitempty"`      // Default resource limits for runs of this vein
+	Description string                `yaml:"description,omitempty"` // One-line summary of the language and what the image offers
+	Tags        []string              `yaml:"tags,omitempty"`        // Categories (e.g., [scripting, functional])
+	Aliases     []string              `yaml:"aliases,omitempty"`     // Other names for the vein (e.g., [py, python3])
+	Modes       []string              `yaml:"modes,omitempty"`       // Modes the image supports; when set, other modes are rejected
+	Platform    string                `yaml:"platform,omitempty"`    // Default image platform (e.g., linux/arm64)
+	Network     string                `yaml:"network,omitempty"`     // Default network mode (e.g., none)
+	Homepage    string                `yaml:"homepage,omitempty"`    // Language or image homepage URL
+}
+
+// Validate checks the required fields and the format of the optional ones.
+func (v *Vein) Validate() error {
+	if v.Name == "" {
+		return fmt.Errorf("vein name is required")
+	}
+	if v.Container == "" {
+		return fmt.Errorf("vein container is required")
+	}
+	if err := v.Limits.Validate(); err != nil {
+		return fmt.Errorf("vein %s: %w", v.Name, err)
+	}
+	if err := checkWords("alias", v.Aliases); err != nil {
+		return fmt.Errorf("vein %s: %w", v.Name, err)
+	}
+	if slices.Contains(v.Aliases, v.Name) {
+		return fmt.Errorf("vein %s: alias %q repeats the vein name", v.Name, v.Name)
+	}
+	if err := checkWords("mode", v.Modes); err != nil {
+		return fmt.Errorf("vein %s: %w", v.Name, err)
+	}
+	for i, tag := range v.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("vein %s: empty tag", v.Name)
+		}
+		if slices.Contains(v.Tags[:i], tag) {
+			return fmt.Errorf("vein %s: duplicate tag %q", v.Name, tag)
+		}
+	}
+	if v.Platform != "" {
+		parts := strings.Split(v.Platform, "/")
+		if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+			return fmt.Errorf("vein %s: invalid platform %q (expected os/arch or os/arch/variant)", v.Name, v.Platform)
+		}
+	}
+	if strings.ContainsFunc(v.Network, unicode.IsSpace) {
+		return fmt.Errorf("vein %s: invalid network %q", v.Name, v.Network)
+	}
+	if v.Homepage != "" {
+		u, err := url.Parse(v.Homepage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("vein %s: invalid homepage %q (expected an http or https URL)", v.Name, v.Homepage)
+		}
+	}
+	return nil
+}
+
+// checkWords rejects empty, repeated or malformed names: no whitespace, and no ':' since
+// "vein:mode" separates a vein from its mode.
+func checkWords(kind string, words []string) error {
+	for i, w := range words {
+		if w == "" || strings.ContainsFunc(w, unicode.IsSpace) || strings.Contains(w, ":") {
+			return fmt.Errorf("invalid %s %q", kind, w)
+		}
+		if slices.Contains(words[:i], w) {
+			return fmt.Errorf("duplicate %s %q", kind, w)
+		}
+	}
+	return nil
+}
+
+// CheckMode reports an error when the vein declares its modes and mode is not one of them.
+func (v *Vein) CheckMode(mode string) error {
+	if mode == "" || len(v.Modes) == 0 || slices.Contains(v.Modes, mode) {
+		return nil
+	}
+	return fmt.Errorf("vein %s has no mode %q (modes: %s)", v.Name, mode, strings.Join(v.Modes, ", "))
 }
 
 // VeinRegistry manages available veins
 type VeinRegistry struct {
-	veins map[string]*Vein
+	veins    map[string]*Vein
+	sources  map[string][]Source // layers that defined and overrode each vein
+	disabled map[string]Source   // veins removed by a layer, and which one
+	aliases  map[string]string   // lowercased alias -> vein name, from AddAlias
 }
 
 // NewVeinRegistry creates an empty registry
 func NewVeinRegistry() *VeinRegistry {
 	return &VeinRegistry{
-		veins: make(map[string]*Vein),
+		veins:    make(map[string]*Vein),
+		sources:  make(map[string][]Source),
+		disabled: make(map[string]Source),
+		aliases:  make(map[string]string),
 	}
 }
 
 // Add adds a vein to the registry
 // Returns an error if a vein with the same name already exists (prevents clobbering)
 func (r *VeinRegistry) Add(vein *Vein) error {
-	if vein.Name == "" {
-		return fmt.Errorf("vein name is required")
-	}
-	if vein.Container == "" {
-		return fmt.Errorf("vein container is required")
+	if err := vein.Validate(); err != nil {
+		return err
 	}
 	if _, exists := r.veins[vein.Name]; exists {
 		return fmt.Errorf("duplicate vein name: %s", vein.Name)
@@ -44,7 +130,7 @@ func (r *VeinRegistry) Add(vein *Vein) error {
 	return nil
 }
 
-// Get retrieves a vein by name
+// Get retrieves a vein by its exact name; Resolve also accepts aliases and any letter case
 func (r *VeinRegistry) Get(name string) (*Vein, bool) {
 	vein, ok := r.veins[name]
 	return vein, ok
@@ -66,17 +152,27 @@ func replaceTag(container, tag string) string {
 	return container[:lastColon] + ":" + tag
 }
 
-func imageExistsLocally(image string) bool {
+// ImageExistsLocally reports whether image is present in the local image store. Answers are
+// cached for the life of the process.
+func ImageExistsLocally(image string) bool {
 	if cached, ok := imageExistsCache.Load(image); ok {
 		return cached.(bool)
 	}
-	cmd := exec.Command("docker", "image", "inspect", "--format", ".", image)
-	err := cmd.Run()
-	exists := err == nil
+	exists := inspectImage(context.Background(), image) == nil
 	imageExistsCache.Store(image, exists)
 	return exists
 }
 
+// inspectImage returns nil when image is present locally. It asks the Engine API and
+// falls back to the docker CLI when the API endpoint is unusable.
+func inspectImage(ctx context.Context, image string) error {
+	if c, err := dockerapi.Default(ctx); err == nil {
+		_, err := c.ImageInspect(ctx, image)
+		return err
+	}
+	return exec.CommandContext(ctx, "docker", "image", "inspect", "--format", ".", image).Run()
+}
+
 // ResolveImageTag picks the right tag for a container image.
 //
 // Priority:
@@ -97,7 +193,7 @@ func ResolveImageTag(container string) string {
 				continue
 			}
 			candidate := replaceTag(container, tag)
-			if imageExistsLocally(candidate) {
+			if ImageExistsLocally(candidate) {
 				return candidate
 			}
 		}
@@ -114,12 +210,13 @@ func ApplyForceTag(container string) string {
 	return ResolveImageTag(container)
 }
 
-// List returns all vein names
+// List returns all vein names, sorted
 func (r *VeinRegistry) List() []string {
 	names := make([]string, 0, len(r.veins))
 	for name := range r.veins {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
@@ -128,14 +225,61 @@ func (r *VeinRegistry) List() []string {
 // fraglet artifacts so re-execution uses the same image. If the image cannot be resolved
 // to a digest (e.g. local-only image), returns the original image reference unchanged.
 func ResolveImageDigest(ctx context.Context, image string) (string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{index .RepoDigests 0}}", image)
-	out, err := cmd.Output()
+	digests, err := ImageRepoDigests(ctx, image)
 	if err != nil {
 		return image, nil // return as-is so save still works
 	}
-	digestRef := strings.TrimSpace(string(out))
-	if digestRef == "" || digestRef == "<no value>" {
-		return image, nil
+	if digest, ok := RepoDigest(image, digests); ok {
+		return digest, nil
+	}
+	return image, nil
+}
+
+// RepoDigest picks, from an image's RepoDigests, the one for the repository image names. An image
+// pushed to several registries has a digest per repository, in no particular order; the first
+// one may belong to another repository. ok is false when none matches.
+func RepoDigest(image string, digests []string) (string, bool) {
+	repo := repoName(image)
+	for _, d := range digests {
+		if at := strings.Index(d, "@"); at > 0 && repoName(d[:at]) == repo {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// repoName drops the tag and digest from an image reference and the docker.io/library/ prefixes
+// Docker Hub references may carry: "docker.io/library/ubuntu:24.04" -> "ubuntu".
+func repoName(ref string) string {
+	if at := strings.Index(ref, "@"); at >= 0 {
+		ref = ref[:at]
+	}
+	if colon := strings.LastIndex(ref, ":"); colon > strings.LastIndex(ref, "/") {
+		ref = ref[:colon]
+	}
+	for _, prefix := range []string{"docker.io/", "index.docker.io/"} {
+		if rest, ok := strings.CutPrefix(ref, prefix); ok {
+			ref = strings.TrimPrefix(rest, "library/")
+			break
+		}
+	}
+	return ref
+}
+
+// ImageRepoDigests returns the registry digest references (repo@sha256:...) of a local image.
+// A local-only image has none; an image that is not present locally is an error.
+func ImageRepoDigests(ctx context.Context, image string) ([]string, error) {
+	if c, err := dockerapi.Default(ctx); err == nil {
+		info, err := c.ImageInspect(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		return info.RepoDigests, nil
+	}
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{range .RepoDigests}}{{println .}}{{end}}", image)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("inspecting %s: %w", image, err)
 	}
-	return digestRef, nil
+	return strings.Fields(string(out)), nil
 }
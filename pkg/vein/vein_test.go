@@ -0,0 +1,117 @@
+package vein
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVeinValidate(t *testing.T) {
+	full := Vein{
+		Name:        "python",
+		Container:   "100hellos/python:latest",
+		Description: "Python 3",
+		Tags:        []string{"scripting"},
+		Aliases:     []string{"py", "python3"},
+		Modes:       []string{"main", "repl"},
+		Platform:    "linux/arm64/v8",
+		Network:     "none",
+		Homepage:    "https://www.python.org",
+	}
+	if err := full.Validate(); err != nil {
+		t.Fatalf("Validate(full) = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Vein)
+		want   string
+	}{
+		{"no name", func(v *Vein) { v.Name = "" }, "vein name is required"},
+		{"no container", func(v *Vein) { v.Container = "" }, "vein container is required"},
+		{"alias repeats name", func(v *Vein) { v.Aliases = []string{"python"} }, "repeats the vein name"},
+		{"duplicate alias", func(v *Vein) { v.Aliases = []string{"py", "py"} }, `duplicate alias "py"`},
+		{"alias with colon", func(v *Vein) { v.Aliases = []string{"py:3"} }, `invalid alias "py:3"`},
+		{"mode with space", func(v *Vein) { v.Modes = []string{"a b"} }, `invalid mode "a b"`},
+		{"empty tag", func(v *Vein) { v.Tags = []string{" "} }, "empty tag"},
+		{"duplicate tag", func(v *Vein) { v.Tags = []string{"x", "x"} }, `duplicate tag "x"`},
+		{"platform", func(v *Vein) { v.Platform = "arm64" }, `invalid platform "arm64"`},
+		{"network", func(v *Vein) { v.Network = "my net" }, `invalid network "my net"`},
+		{"homepage", func(v *Vein) { v.Homepage = "www.python.org" }, `invalid homepage "www.python.org"`},
+		{"limits", func(v *Vein) { v.Limits.Memory = "lots" }, "memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := full
+			tt.modify(&v)
+			err := v.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVeinCheckMode(t *testing.T) {
+	v := &Vein{Name: "python", Modes: []string{"main", "repl"}}
+	if err := v.CheckMode("repl"); err != nil {
+		t.Errorf("CheckMode(repl) = %v", err)
+	}
+	if err := v.CheckMode(""); err != nil {
+		t.Errorf("CheckMode(\"\") = %v", err)
+	}
+	err := v.CheckMode("notebook")
+	if err == nil || err.Error() != `vein python has no mode "notebook" (modes: main, repl)` {
+		t.Errorf("CheckMode(notebook) = %v", err)
+	}
+	if err := (&Vein{Name: "c"}).CheckMode("anything"); err != nil {
+		t.Errorf("undeclared modes: CheckMode = %v", err)
+	}
+}
+
+func TestRepoDigest(t *testing.T) {
+	digests := []string{"ghcr.io/mirror/python@sha256:aaa", "100hellos/python@sha256:bbb"}
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"100hellos/python:latest", "100hellos/python@sha256:bbb"},
+		{"docker.io/100hellos/python:local", "100hellos/python@sha256:bbb"},
+		{"ghcr.io/mirror/python", "ghcr.io/mirror/python@sha256:aaa"},
+		{"localhost:5000/python:latest", ""},
+	}
+	for _, tt := range tests {
+		got, ok := RepoDigest(tt.image, digests)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("RepoDigest(%q) = %q, %v, want %q", tt.image, got, ok, tt.want)
+		}
+	}
+	if got, ok := RepoDigest("docker.io/library/ubuntu:24.04", []string{"ubuntu@sha256:ccc"}); !ok || got != "ubuntu@sha256:ccc" {
+		t.Errorf("library image: %q, %v", got, ok)
+	}
+}
+
+func TestNewExtensionMap_FirstAlphabeticallyWins(t *testing.T) {
+	r := NewVeinRegistry()
+	for _, v := range []*Vein{
+		{Name: "befunge", Container: "b", Extensions: []string{".bf"}},
+		{Name: "brainfuck", Container: "bf", Extensions: []string{".bf"}, Aliases: []string{"bf"}},
+		{Name: "deno", Container: "d", Extensions: []string{".ts", ".js"}},
+		{Name: "typescript", Container: "t", Extensions: []string{".ts"}, Aliases: []string{"ts"}},
+		{Name: "octave", Container: "o", Extensions: []string{".m"}},
+		{Name: "objective-c", Container: "c", Extensions: []string{".m"}},
+	} {
+		if err := r.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewExtensionMap(r)
+	for ext, want := range map[string]string{".bf": "befunge", ".ts": "deno", ".js": "deno", ".m": "objective-c"} {
+		if got, err := m.VeinForExtension(ext); err != nil || got != want {
+			t.Errorf("VeinForExtension(%s) = %q, %v; want %s", ext, got, err, want)
+		}
+	}
+	// Aliases do not change the pick: a vein named for the extension does not win it.
+	if got := strings.Join(m.Conflicts(".bf"), ","); got != "befunge,brainfuck" {
+		t.Errorf("Conflicts(.bf) = %s", got)
+	}
+}